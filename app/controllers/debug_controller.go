@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+type beliefsResponse struct {
+	Player           models.PlayerPosition         `json:"player"`
+	EffectiveSamples float64                       `json:"effective_samples"`
+	HandSizes        map[models.PlayerPosition]int `json:"hand_sizes"`
+	// Unavailable are the pips each seat lacks given its passes
+	Unavailable map[models.PlayerPosition][]int `json:"unavailable"`
+	// Bones has, for every unseen bone, the probability of each seat holding it
+	Bones map[string]map[models.PlayerPosition]float64 `json:"bones"`
+}
+
+// BeliefsHandler answers the same request as GameHandler with where the
+// player to move thinks the unseen bones are. main serves it only with
+// DOMINO_DEBUG set.
+func BeliefsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	domino, ok := decodeGameState(w, r)
+	if !ok {
+		return
+	}
+
+	jsonResp, err := json.Marshal(beliefsToResponse(inference.Infer(domino, nil)))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		log.Printf("Error happened in JSON marshal. Err: %s\n", err)
+
+		return
+	}
+
+	w.Write(jsonResp)
+}
+
+func beliefsToResponse(beliefs *inference.Beliefs) *beliefsResponse {
+	resp := &beliefsResponse{
+		Player:           beliefs.Player,
+		EffectiveSamples: beliefs.EffectiveSamples,
+		HandSizes:        make(map[models.PlayerPosition]int, models.DominoMaxPlayer),
+		Unavailable:      make(map[models.PlayerPosition][]int, models.DominoMaxPlayer),
+		Bones:            make(map[string]map[models.PlayerPosition]float64, beliefs.Unseen.Len()),
+	}
+
+	for i := range beliefs.HandSizes {
+		player := models.PlayerPosition(i + models.DominoMinPlayer)
+		resp.HandSizes[player] = beliefs.HandSizes[i]
+
+		pips := []int{}
+		for pip := models.DominoMinBone; pip < models.DominoMaxPips; pip++ {
+			if beliefs.Unavailable[i].Has(pip) {
+				pips = append(pips, pip)
+			}
+		}
+		resp.Unavailable[player] = pips
+	}
+
+	for rest := beliefs.Unseen; !rest.Empty(); {
+		var bone models.Domino
+		bone, rest = rest.Pop()
+
+		probabilities := make(map[models.PlayerPosition]float64, models.DominoMaxPlayer-1)
+		for i := 1; i < models.DominoMaxPlayer; i++ {
+			player := beliefs.Player.Add(i)
+			probabilities[player] = beliefs.Probability(bone, player)
+		}
+
+		resp.Bones[models.DominoToString(bone)] = probabilities
+	}
+
+	return resp
+}
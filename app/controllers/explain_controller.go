@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/models"
+)
+
+type explainResponse struct {
+	// Rule of the heuristic strategy that chose Play
+	Rule  game.Rule          `json:"rule"`
+	Play  *playStateResponse `json:"jogada"`
+	Moves []moveResponse     `json:"jogadas"`
+}
+
+type moveResponse struct {
+	Play    *playStateResponse `json:"jogada"`
+	Chosen  bool               `json:"chosen"`
+	Sum     int                `json:"sum"`
+	Locks   bool               `json:"locks"`
+	Partner bool               `json:"partner"`
+	Passes  int                `json:"passes"`
+	Reasons []string           `json:"reasons"`
+}
+
+// ExplainHandler answers the same request as GameHandler with every legal
+// play, what the heuristics saw in it and the rule that chose the play. It
+// runs on a session of its own, the match isn't touched.
+func ExplainHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	domino, ok := decodeGameState(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), game.PlayTimeout)
+	defer cancel()
+
+	explanation := game.NewSession().Explain(ctx, domino)
+
+	jsonResp, err := json.Marshal(explanationToResponse(domino, explanation))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		log.Printf("Error happened in JSON marshal. Err: %s\n", err)
+
+		return
+	}
+
+	w.Write(jsonResp)
+}
+
+func explanationToResponse(state *models.DominoGameState, explanation game.Explanation) *explainResponse {
+	resp := &explainResponse{
+		Rule:  explanation.Rule,
+		Play:  dominoPlayToResponse(state, explanation.Play),
+		Moves: make([]moveResponse, 0, len(explanation.Moves)),
+	}
+
+	for _, move := range explanation.Moves {
+		chosen := !explanation.Play.Pass() &&
+			move.Play.Bone.Edge == explanation.Play.Bone.Edge &&
+			move.Play.Bone.Domino.Equals(explanation.Play.Bone.Domino)
+
+		resp.Moves = append(resp.Moves, moveResponse{
+			Play:    dominoPlayToResponse(state, move.Play),
+			Chosen:  chosen,
+			Sum:     move.Sum,
+			Locks:   move.Locks,
+			Partner: move.Partner,
+			Passes:  move.Passes,
+			Reasons: move.Reasons,
+		})
+	}
+
+	return resp
+}
@@ -1,16 +1,50 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sync"
 
 	"github.com/josecleiton/domino/app/game"
 	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
 )
 
+// MatchIDHeader lets the referee name the match, otherwise it's derived
+// from the deal of the seat
+const MatchIDHeader = "X-Match-Id"
+
+// OpponentHeader names the bot the seat faces, its profile in Profiles
+// biases the search strategies
+const OpponentHeader = "X-Opponent"
+
+// RulesHeader and ScoreHeader tell the seat the goal of a match of many
+// hands: the ruleset, by name or rules, and the points of its team and of
+// the other one, like "3-5"
+const (
+	RulesHeader = "X-Rules"
+	ScoreHeader = "X-Score"
+)
+
+// StrictHeader, set to anything, rejects the request if it's invalid as
+// Strict does for every request
+const StrictHeader = "X-Strict"
+
+// StrategyQuery picks a registered strategy other than game.DefaultStrategy
+const StrategyQuery = "strategy"
+
+// Profiles are the opponent profiles OpponentHeader may name
+var Profiles profile.Profiles
+
+// StateDump receives, when set, the game record of every answered state
+var StateDump io.Writer
+
+var stateDumpMu sync.Mutex
+
 type gameStateRequest struct {
 	Player int                `json:"jogador"`
 	Hand   []string           `json:"mao"`
@@ -42,27 +76,18 @@ func GameHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	var request gameStateRequest
-
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&request)
-	if err != nil {
-		log.Printf("Error happened in JSON marshal. Err: %s\n", err)
-
-		const status = http.StatusBadRequest
-		w.WriteHeader(status)
-		w.Write(parseError(err, status))
-
+	domino, ok := decodeGameState(w, r)
+	if !ok {
 		return
 	}
 
-	domino, err := gameRequestToDomain(&request)
-	if err != nil {
-		log.Printf("Error happened in play. Err: %s\n", err)
-
+	strategy := r.URL.Query().Get(StrategyQuery)
+	if strategy == "" {
+		strategy = game.DefaultStrategy
+	} else if !game.HasStrategy(strategy) {
 		const status = http.StatusBadRequest
 		w.WriteHeader(status)
-		w.Write(parseError(err, status))
+		w.Write(parseError(fmt.Errorf("unknown strategy %q", strategy), status))
 
 		return
 	}
@@ -81,7 +106,16 @@ func GameHandler(w http.ResponseWriter, r *http.Request) {
 		)
 	}()
 
-	play := game.Play(domino)
+	ctx, cancel := context.WithTimeout(r.Context(), game.PlayTimeout)
+	defer cancel()
+
+	session := game.DefaultSessions.Session(r.Header.Get(MatchIDHeader), domino)
+	var play models.DominoPlayWithPass
+	if opponent := r.Header.Get(OpponentHeader); opponent != "" {
+		play = session.PlayGuardedAgainst(ctx, strategy, domino, Profiles[opponent])
+	} else {
+		play = session.PlayGuarded(ctx, strategy, domino)
+	}
 
 	resp := dominoPlayToResponse(domino, play)
 
@@ -97,6 +131,83 @@ func GameHandler(w http.ResponseWriter, r *http.Request) {
 	wg.Wait()
 
 	log.Printf("[RES] %v\n", play)
+
+	dumpState(r.Header.Get(MatchIDHeader), domino, play)
+}
+
+func dumpState(source string, state *models.DominoGameState, play models.DominoPlayWithPass) {
+	if StateDump == nil {
+		return
+	}
+
+	record := models.GameRecordFromState(state)
+	record.Source = source
+	answer := models.PlayRecordFromPlay(play)
+	answer.Player = state.PlayerPosition
+	record.Answer = &answer
+
+	stateDumpMu.Lock()
+	defer stateDumpMu.Unlock()
+
+	if err := models.WriteGameRecord(StateDump, record); err != nil {
+		log.Printf("Error happened in state dump. Err: %s\n", err)
+	}
+}
+
+// decodeGameState writes the error response when the request is invalid
+func decodeGameState(w http.ResponseWriter, r *http.Request) (*models.DominoGameState, bool) {
+	var request gameStateRequest
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&request)
+	if err != nil {
+		log.Printf("Error happened in JSON marshal. Err: %s\n", err)
+
+		const status = http.StatusBadRequest
+		w.WriteHeader(status)
+		w.Write(parseError(err, status))
+
+		return nil, false
+	}
+
+	goal, err := goalFromHeader(r.Header)
+	if err != nil {
+		log.Printf("Error happened in play. Err: %s\n", err)
+
+		const status = http.StatusBadRequest
+		w.WriteHeader(status)
+		w.Write(parseError(err, status))
+
+		return nil, false
+	}
+
+	domino, errs := gameRequestToDomain(&request, goal)
+	strict := Strict || r.Header.Get(StrictHeader) != ""
+	if len(errs) > 0 && (strict || domino == nil) {
+		log.Printf("Error happened in play. Err: %s\n", errs)
+
+		const status = http.StatusUnprocessableEntity
+		w.WriteHeader(status)
+		w.Write(validationError(errs, status))
+
+		return nil, false
+	}
+
+	for _, err := range errs {
+		log.Printf("Lenient on request. Err: %s\n", err)
+	}
+
+	return domino, true
+}
+
+// goalFromHeader is nil without RulesHeader, a single hand
+func goalFromHeader(header http.Header) (*models.Goal, error) {
+	rules := header.Get(RulesHeader)
+	if rules == "" {
+		return nil, nil
+	}
+
+	return models.ParseGoal(rules, header.Get(ScoreHeader))
 }
 
 func parseError(err error, status int) []byte {
@@ -115,91 +226,64 @@ func parseError(err error, status int) []byte {
 	return jsonResp
 }
 
-func gameRequestToDomain(request *gameStateRequest) (*models.DominoGameState, error) {
-	if request.Player < models.DominoMinPlayer || request.Player > models.DominoMaxPlayer {
-		return nil, fmt.Errorf(
-			"player must be between %d and %d, not %d",
-			models.DominoMinPlayer,
-			models.DominoMaxPlayer,
-			request.Player,
-		)
+// validationError is parseError with every problem in errors
+func validationError(errs ValidationErrors, status int) []byte {
+	errorMap := map[string]interface{}{
+		"error":  errs.Error(),
+		"status": http.StatusText(status),
+		"code":   status,
+		"errors": errs,
 	}
 
-	hand := make([]models.Domino, 0, len(request.Hand))
-	tableMap := make(models.TableMap, models.DominoUniqueBones)
-	table := make([]models.Domino, 0, len(request.Table))
-	possibleEdges := []models.Edge{models.LeftEdge, models.RightEdge}
-	edges := make(models.Edges, len(possibleEdges))
-	plays := make([]models.DominoPlay, 0, len(request.Plays))
-
-	for _, bone := range request.Hand {
-		domino, err := models.DominoFromString(bone)
-		if err != nil {
-			return nil, err
-		}
-
-		hand = append(hand, *domino)
+	jsonResp, marshalErr := json.Marshal(errorMap)
+	if marshalErr != nil {
+		log.Printf("Error happened in JSON marshal. Err: %s\n", marshalErr)
+		return []byte(errs.Error())
 	}
 
-	for _, play := range request.Plays {
-		domino, err := models.DominoFromString(play.Bone)
-		if err != nil {
-			return nil, err
-		}
-
-		if _, ok := tableMap[domino.L]; !ok {
-			tableMap[domino.L] = make(models.TableBone, models.DominoUniqueBones)
-		}
-		if _, ok := tableMap[domino.R]; !ok {
-			tableMap[domino.R] = make(models.TableBone, models.DominoUniqueBones)
-		}
-
-		tableMap[domino.L][domino.R] = true
-		tableMap[domino.R][domino.L] = true
+	return jsonResp
+}
 
-		edge := models.LeftEdge
-		if play.Direction != nil && *play.Direction == Right {
-			edge = models.RightEdge
-		}
+// gameRequestToDomain checks the request, the state is nil when it can't be
+// repaired. The table the plays build is the one every strategy sees, the
+// mesa only when there are no plays.
+func gameRequestToDomain(request *gameStateRequest, goal *models.Goal) (*models.DominoGameState, ValidationErrors) {
+	state := &models.DominoGameState{
+		PlayerPosition: models.PlayerPosition(request.Player),
+		Goal:           goal,
+	}
+	v := validator{set: state.Set()}
 
-		plays = append(plays, models.DominoPlay{
-			PlayerPosition: models.PlayerPosition(play.Player),
-			Bone: models.DominoInTable{
-				Domino: *domino,
-				Edge:   edge,
-			},
-		})
+	if request.Player < models.DominoMinPlayer || request.Player > models.DominoMaxPlayer {
+		v.add(
+			CodeInvalidPlayer,
+			"jogador",
+			"player must be between %d and %d, not %d",
+			models.DominoMinPlayer,
+			models.DominoMaxPlayer,
+			request.Player,
+		)
 
+		return nil, v.errors
 	}
 
-	for _, v := range request.Table {
-		domino, err := models.DominoFromString(v)
-		if err != nil {
-			return nil, err
-		}
+	plays, indices := v.plays(request.Plays)
+	table := v.bones("mesa", request.Table, models.BoneSet{})
 
-		table = append(table, *domino)
+	chain := models.ReplayTable(plays).Chain
+	v.table(chain, table)
+	if len(plays) > 0 {
+		table = chain
 	}
 
-	playsRequestLen := len(request.Plays)
-	if playsRequestLen > 1 {
-		edges[models.LeftEdge] = &plays[0].Bone.Domino
-		edges[models.RightEdge] = &plays[len(plays)-1].Bone.Domino
-	} else if playsRequestLen == 1 {
-		leftEdge := plays[0].Bone.Domino
-		rightEdge := leftEdge
+	state.Plays = plays
+	state.Table = table
+	state.TableMap = models.TableMapFromDominoes(table)
+	state.Hand = v.bones("mao", request.Hand, models.BoneSetOf(table...))
 
-		edges[models.LeftEdge] = &leftEdge
-		edges[models.RightEdge] = &rightEdge
-	}
+	v.turns(state, indices)
 
-	return &models.DominoGameState{
-		PlayerPosition: models.PlayerPosition(request.Player),
-		Hand:           hand,
-		TableMap:       tableMap,
-		Table:          table,
-		Plays:          plays,
-	}, nil
+	return state, v.errors
 }
 
 func dominoPlayToResponse(state *models.DominoGameState, dominoPlay models.DominoPlayWithPass) *playStateResponse {
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+// Strict rejects invalid requests with every problem found instead of
+// answering them. Off by default: a false positive answered with 422
+// disqualifies the bot, so the problems are logged and what can be
+// repaired is played on.
+var Strict bool
+
+type ValidationCode string
+
+const (
+	CodeInvalidPlayer ValidationCode = "invalid_player"
+	CodeInvalidBone   ValidationCode = "invalid_bone"
+	CodeInvalidSide   ValidationCode = "invalid_side"
+	CodeBoneOutOfSet  ValidationCode = "bone_out_of_set"
+	CodeDuplicateBone ValidationCode = "duplicate_bone"
+	CodeHandOnTable   ValidationCode = "hand_on_table"
+	CodeHandSize      ValidationCode = "hand_size"
+	CodeOutOfTurn     ValidationCode = "play_out_of_turn"
+	CodeUnglued       ValidationCode = "play_unglued"
+	CodeTableMismatch ValidationCode = "table_mismatch"
+)
+
+// ValidationError is a problem of the request, Field is the path of the
+// value in the JSON body, like "jogadas[3].pedra"
+type ValidationError struct {
+	Code    ValidationCode `json:"code"`
+	Field   string         `json:"field"`
+	Message string         `json:"message"`
+}
+
+type ValidationErrors []ValidationError
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+type validator struct {
+	set    models.DominoSet
+	errors ValidationErrors
+}
+
+func (v *validator) add(code ValidationCode, field string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{
+		Code:    code,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// bone parses the bone of field, false when it's invalid
+func (v *validator) bone(field, s string) (models.Domino, bool) {
+	domino, err := models.DominoFromString(s)
+	if err != nil {
+		v.add(CodeInvalidBone, field, "%s", err)
+		return models.Domino{}, false
+	}
+
+	if !v.set.Has(*domino) {
+		v.add(CodeBoneOutOfSet, field, "bone %s is not of the %s set", models.DominoToString(*domino), v.set)
+		return models.Domino{}, false
+	}
+
+	return *domino, true
+}
+
+// bones parses the bones of field, dropping the invalid and repeated ones
+// and the ones on the table
+func (v *validator) bones(field string, values []string, table models.BoneSet) []models.Domino {
+	bones := make([]models.Domino, 0, len(values))
+
+	var seen models.BoneSet
+	for i, s := range values {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		bone, ok := v.bone(path, s)
+		if !ok {
+			continue
+		}
+
+		if seen.Has(bone) {
+			v.add(CodeDuplicateBone, path, "bone %s is repeated", s)
+			continue
+		}
+
+		if table.Has(bone) {
+			v.add(CodeHandOnTable, path, "bone %s is on the table", s)
+			continue
+		}
+
+		seen = seen.Add(bone)
+		bones = append(bones, bone)
+	}
+
+	return bones
+}
+
+// plays parses the plays, dropping the invalid ones and the ones the table
+// can't take. indices has the index in the request of each play kept.
+func (v *validator) plays(request []playStateRequest) (plays []models.DominoPlay, indices []int) {
+	for i, play := range request {
+		field := fmt.Sprintf("jogadas[%d]", i)
+		if play.Player < models.DominoMinPlayer || play.Player > models.DominoMaxPlayer {
+			v.add(CodeInvalidPlayer, field+".jogador", "player must be between %d and %d, not %d",
+				models.DominoMinPlayer, models.DominoMaxPlayer, play.Player)
+			continue
+		}
+
+		bone, ok := v.bone(field+".pedra", play.Bone)
+		if !ok {
+			continue
+		}
+
+		edge := models.LeftEdge
+		if play.Direction != nil {
+			switch *play.Direction {
+			case Left:
+			case Right:
+				edge = models.RightEdge
+			default:
+				v.add(CodeInvalidSide, field+".lado", "side must be %q or %q, not %q", Left, Right, *play.Direction)
+				continue
+			}
+		}
+
+		plays = append(plays, models.DominoPlay{
+			PlayerPosition: models.PlayerPosition(play.Player),
+			Bone:           models.DominoInTable{Domino: bone, Edge: edge},
+		})
+		indices = append(indices, i)
+	}
+
+	replay := models.ReplayTable(plays)
+	if len(replay.Discrepancies) == 0 {
+		return plays, indices
+	}
+
+	skipped := make(map[int]bool, len(replay.Discrepancies))
+	for _, discrepancy := range replay.Discrepancies {
+		field := fmt.Sprintf("jogadas[%d].pedra", indices[discrepancy.Play])
+
+		code := CodeUnglued
+		if discrepancy.Kind == models.DiscrepancyRepeated {
+			code = CodeDuplicateBone
+		}
+
+		v.add(code, field, "%s", discrepancy)
+		skipped[discrepancy.Play] = true
+	}
+
+	kept, keptIndices := plays[:0], indices[:0]
+	for i := range plays {
+		if !skipped[i] {
+			kept, keptIndices = append(kept, plays[i]), append(keptIndices, indices[i])
+		}
+	}
+
+	return kept, keptIndices
+}
+
+// table checks the mesa against the chain of the plays, a mesa turned is
+// only logged
+func (v *validator) table(chain, table []models.Domino) {
+	replay := models.TableReplay{Chain: chain}
+	for _, discrepancy := range replay.Check(table) {
+		if discrepancy.Kind == models.DiscrepancyFlipped {
+			log.Printf("Table discrepancy: %s\n", discrepancy)
+			continue
+		}
+
+		field := "mesa"
+		if discrepancy.Position >= 0 {
+			field = fmt.Sprintf("mesa[%d]", discrepancy.Position)
+		}
+
+		v.add(CodeTableMismatch, field, "%s", discrepancy)
+	}
+}
+
+// turns checks the hand sizes the plays leave and the order of the plays.
+// The seats skipped between two plays passed, a seat playing twice in a row
+// means the other three did: no seat may play a pip it passed on before and
+// the player can't have passed holding a bone that glued, otherwise the
+// plays are out of turn.
+func (v *validator) turns(state *models.DominoGameState, indices []int) {
+	handLength := v.set.HandLength()
+	hand := models.BoneSetOf(state.Hand...)
+
+	var played [models.DominoMaxPlayer]int
+	var lacks [models.DominoMaxPlayer]models.PipSet
+	next := 0
+	for _, turn := range inference.History(state) {
+		seat := turn.Player - models.DominoMinPlayer
+		if turn.Pass() {
+			lacks[seat] = lacks[seat].Add(turn.Left).Add(turn.Right)
+
+			// the bones of the hand were held then too
+			if turn.Player == state.PlayerPosition && next < len(indices) {
+				if bone, ok := gluing(hand, turn.Left, turn.Right); ok {
+					v.add(
+						CodeOutOfTurn,
+						fmt.Sprintf("jogadas[%d].jogador", indices[next]),
+						"player %d skips player %d, who holds %s",
+						state.Plays[next].PlayerPosition,
+						turn.Player,
+						models.DominoToString(bone),
+					)
+				}
+			}
+
+			continue
+		}
+
+		field := fmt.Sprintf("jogadas[%d]", indices[next])
+		next++
+
+		if played[seat] == handLength {
+			v.add(CodeOutOfTurn, field+".jogador", "player %d has no bones left", turn.Player)
+			continue
+		}
+		played[seat]++
+
+		if lacks[seat].Has(turn.Bone.L) || lacks[seat].Has(turn.Bone.R) {
+			v.add(
+				CodeOutOfTurn,
+				field+".jogador",
+				"player %d plays %s after passing on it",
+				turn.Player,
+				models.DominoToString(*turn.Bone),
+			)
+		}
+	}
+
+	want := handLength - played[state.PlayerPosition-models.DominoMinPlayer]
+	switch {
+	case len(state.Hand) == 0:
+		v.add(CodeHandSize, "mao", "hand is empty, the game is over")
+	case len(state.Hand) != want:
+		v.add(CodeHandSize, "mao", "hand must have %d bones, not %d", want, len(state.Hand))
+	}
+}
+
+// gluing is a bone of hand with the pip left or right
+func gluing(hand models.BoneSet, left, right int) (models.Domino, bool) {
+	for rest := hand; !rest.Empty(); {
+		var bone models.Domino
+		bone, rest = rest.Pop()
+
+		if bone.L == left || bone.R == left || bone.L == right || bone.R == right {
+			return bone, true
+		}
+	}
+
+	return models.Domino{}, false
+}
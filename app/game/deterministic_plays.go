@@ -6,17 +6,17 @@ import (
 	"github.com/josecleiton/domino/app/models"
 )
 
-func oneSidedPlay(left, right []models.DominoInTable) models.DominoPlayWithPass {
+func (g *Session) oneSidedPlay(left, right []models.DominoInTable) models.DominoPlayWithPass {
 	if len(left) > 0 {
-		return commonMaximizedPlay(left)
+		return g.commonMaximizedPlay(left)
 	}
 
-	return commonMaximizedPlay(right)
+	return g.commonMaximizedPlay(right)
 }
 
-func commonMaximizedPlay(bones []models.DominoInTable) models.DominoPlayWithPass {
+func (g *Session) commonMaximizedPlay(bones []models.DominoInTable) models.DominoPlayWithPass {
 
-	commonBones := make([]indexedCount, models.DominoUniqueBones)
+	commonBones := make([]indexedCount, models.DominoMaxPips)
 	for _, eb := range bones {
 		for _, hb := range g.Hand {
 			if bone := eb.Glue(hb); bone != nil {
@@ -38,11 +38,11 @@ func commonMaximizedPlay(bones []models.DominoInTable) models.DominoPlayWithPass
 				continue
 			}
 
-			return playFromDominoInTable(bone)
+			return g.playFromDominoInTable(bone)
 		}
 	}
 
-	return playFromDominoInTable(bones[0])
+	return g.playFromDominoInTable(bones[0])
 }
 
 func maximizedPlays(
@@ -58,10 +58,33 @@ func maximizedPlays(
 	return &max
 }
 
-func countPlay(
+func (g *Session) countPlay(
 	state *models.DominoGameState,
 	left, right []models.DominoInTable,
 ) *models.DominoPlayWithPass {
+	leftLocked, rightLocked := lockedEdges(state, left, right)
+
+	if leftLocked {
+		play := g.playFromDominoInTable(right[0])
+		return &play
+	}
+
+	if rightLocked {
+		play := g.playFromDominoInTable(left[0])
+		return &play
+	}
+
+	return nil
+}
+
+// lockedEdges tells the edges whose bones are all on the table or in the
+// hand, playing on the other edge keeps them locked
+func lockedEdges(
+	state *models.DominoGameState,
+	left, right []models.DominoInTable,
+) (bool, bool) {
+	leftLocked, rightLocked := false, false
+
 	if ep, ok := state.Edges()[models.LeftEdge]; ok && ep != nil {
 		leftBonesInGame := countBones(state, models.DominoInTable{
 			Edge: models.LeftEdge,
@@ -70,10 +93,7 @@ func countPlay(
 				R: ep.L,
 			},
 		})
-		if len(left)+leftBonesInGame == models.DominoUniqueBones {
-			play := playFromDominoInTable(right[0])
-			return &play
-		}
+		leftLocked = len(left)+leftBonesInGame == state.Set().UniqueBones()
 	}
 
 	if ep, ok := state.Edges()[models.RightEdge]; ok && ep != nil {
@@ -84,55 +104,53 @@ func countPlay(
 				R: ep.R,
 			},
 		})
-		if len(right)+rightBonesInGame == models.DominoUniqueBones {
-			play := playFromDominoInTable(left[0])
-			return &play
-		}
+		rightLocked = len(right)+rightBonesInGame == state.Set().UniqueBones()
 	}
 
-	return nil
+	return leftLocked, rightLocked
 }
 
-func duoPlay(
+func (g *Session) duoPlay(
 	state *models.DominoGameState,
 	left, right []models.DominoInTable,
 ) *models.DominoPlayWithPass {
-	filteredLeft, filteredRight := duoCanPlayWithBoneGlue(left, right)
+	filteredLeft, filteredRight := g.duoCanPlayWithBoneGlue(left, right)
 	cantPlayLeft, cantPlayRight := len(filteredLeft) == 0,
 		len(filteredRight) == 0
 	playsRespectingDuo := make([]models.DominoPlayWithPass, 0, 2)
 
 	// duo cant play with bone glue
 	if !cantPlayLeft && !cantPlayRight {
-		leftEdge, rightEdge := duoCanPlayEdges(state)
+		leftEdge, rightEdge := g.duoCanPlayEdges(state)
 
 		if leftEdge {
 			playsRespectingDuo = append(
 				playsRespectingDuo,
-				playFromDominoInTable(right[0]),
+				g.playFromDominoInTable(right[0]),
 			)
 		}
 
 		if rightEdge {
 			playsRespectingDuo = append(
 				playsRespectingDuo,
-				playFromDominoInTable(left[0]),
+				g.playFromDominoInTable(left[0]),
 			)
 		}
 
 	}
 
-	if cantPlayLeft {
+	// with no edge the duo can follow both are empty, nothing respects it
+	if cantPlayLeft && !cantPlayRight {
 		playsRespectingDuo = append(
 			playsRespectingDuo,
-			playFromDominoInTable(filteredRight[0]),
+			g.playFromDominoInTable(filteredRight[0]),
 		)
 	}
 
-	if cantPlayRight {
+	if cantPlayRight && !cantPlayLeft {
 		playsRespectingDuo = append(
 			playsRespectingDuo,
-			playFromDominoInTable(filteredLeft[0]),
+			g.playFromDominoInTable(filteredLeft[0]),
 		)
 	}
 
@@ -144,15 +162,15 @@ func duoPlay(
 
 }
 
-func passedPlay(
+func (g *Session) passedPlay(
 	state *models.DominoGameState,
 	left, right []models.DominoInTable,
 ) *models.DominoPlayWithPass {
 	leftCount, rightCount := append([]models.DominoInTable{}, left...),
 		append([]models.DominoInTable{}, right...)
 
-	sortByPassed(leftCount)
-	sortByPassed(rightCount)
+	g.sortByPassed(leftCount)
+	g.sortByPassed(rightCount)
 
 	maxBones := make([]models.DominoInTable, 0, 2)
 	leftCountLen, rightCountLen := len(leftCount), len(rightCount)
@@ -171,12 +189,12 @@ func passedPlay(
 	}
 
 	if maxBonesLen != 1 {
-		sortByPassed(maxBones)
+		g.sortByPassed(maxBones)
 	}
 
 	maxBone := &maxBones[0]
 
-	play := playFromDominoInTable(*maxBone)
+	play := g.playFromDominoInTable(*maxBone)
 
 	return &play
 }
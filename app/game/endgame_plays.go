@@ -0,0 +1,213 @@
+package game
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+const (
+	// README gives 5s per play and run_domino.js times out at 3s
+	endgameBudget   = 2 * time.Second
+	endgameMaxDeals = 2048
+	// how many nodes between deadline checks
+	endgameClockRate = 1 << 10
+)
+
+type endgameSearch struct {
+	ctx      context.Context
+	Player   models.PlayerPosition
+	Deadline time.Time
+	Aborted  bool
+	nodes    int
+	buffers  [][]playoutMove
+}
+
+// maximizeWinningChancesPlay solves the endgame exactly: every deal of the
+// hidden bones consistent with the passes is searched with alpha-beta and
+// the play that wins the most of them, or is worth the most towards the goal
+// of the match, is chosen. Returns nil when there are
+// too many deals, the search runs out of time or all plays are equivalent.
+func (g *Session) maximizeWinningChancesPlay(
+	ctx context.Context,
+	state *models.DominoGameState,
+) *models.DominoPlayWithPass {
+	knowledge := newHandKnowledge(state, g.unavailableBones())
+
+	deals := knowledge.deals(endgameMaxDeals)
+	if len(deals) == 0 {
+		return nil
+	}
+
+	search := endgameSearch{
+		ctx:      ctx,
+		Player:   state.PlayerPosition,
+		Deadline: searchDeadline(ctx, endgameBudget),
+	}
+
+	root := newPlayout(state, deals[0])
+	moves := root.moves(nil)
+	if len(moves) < 2 {
+		return nil
+	}
+
+	wins := make([]float64, len(moves))
+	for _, deal := range deals {
+		p := newPlayout(state, deal)
+
+		for i, move := range moves {
+			undo := p.play(move)
+			wins[i] += search.alphaBeta(&p, playoutLoss, playoutWin, 0)
+			p.undo(undo)
+
+			if search.Aborted {
+				log.Printf("Endgame search aborted after %d nodes\n", search.nodes)
+				return nil
+			}
+		}
+	}
+
+	best, worst := 0, 0
+	for i := range wins {
+		if wins[i] > wins[best] {
+			best = i
+		}
+		if wins[i] < wins[worst] {
+			worst = i
+		}
+	}
+
+	if wins[best] == wins[worst] {
+		return nil
+	}
+
+	play := moves[best].play(state.PlayerPosition)
+
+	return &play
+}
+
+func (s *endgameSearch) alphaBeta(p *playout, alpha, beta float64, depth int) float64 {
+	if s.nodes++; s.nodes%endgameClockRate == 0 &&
+		(time.Now().After(s.Deadline) || s.ctx.Err() != nil) {
+		s.Aborted = true
+	}
+
+	if s.Aborted {
+		return playoutLoss
+	}
+
+	if p.over() {
+		return p.score(s.Player)
+	}
+
+	if len(s.buffers) <= depth {
+		s.buffers = append(s.buffers, make([]playoutMove, 0, models.DominoHandLength*models.DominoMaxEdges))
+	}
+
+	moves := p.moves(s.buffers[depth])
+	s.buffers[depth] = moves
+
+	if len(moves) == 0 {
+		undo := p.pass()
+		value := s.alphaBeta(p, alpha, beta, depth+1)
+		p.undo(undo)
+
+		return value
+	}
+
+	maximizing := sameTeam(p.Player, s.Player)
+	for _, move := range moves {
+		undo := p.play(move)
+		value := s.alphaBeta(p, alpha, beta, depth+1)
+		p.undo(undo)
+
+		if maximizing && value > alpha {
+			alpha = value
+		} else if !maximizing && value < beta {
+			beta = value
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if maximizing {
+		return alpha
+	}
+
+	return beta
+}
+
+// deals enumerates every way to hand out the unseen bones that respects the
+// hand sizes and the pips each seat lacks, nil when there are more than limit
+func (k handKnowledge) deals(limit int) [][models.DominoMaxPlayer]models.BoneSet {
+	var others [models.DominoMaxPlayer - 1]models.PlayerPosition
+	for i := range others {
+		others[i] = k.Player.Add(i + 1)
+	}
+
+	total, left := 1, k.Unseen.Len()
+	for _, player := range others {
+		size := k.HandSizes[player-models.DominoMinPlayer]
+		if size < 0 || size > left {
+			return nil
+		}
+
+		total *= combin.Binomial(left, size)
+		left -= size
+
+		if total > limit {
+			return nil
+		}
+	}
+
+	var hands [models.DominoMaxPlayer]models.BoneSet
+	hands[k.Player-models.DominoMinPlayer] = k.Hand
+
+	deals := make([][models.DominoMaxPlayer]models.BoneSet, 0, total)
+
+	var deal func(i int, rest models.BoneSet)
+	deal = func(i int, rest models.BoneSet) {
+		if i == len(others) {
+			deals = append(deals, hands)
+			return
+		}
+
+		player := others[i]
+		idx := player - models.DominoMinPlayer
+
+		subsets(rest.Intersect(k.holdable(player)), k.HandSizes[idx], func(hand models.BoneSet) {
+			hands[idx] = hand
+			deal(i+1, rest.Minus(hand))
+		})
+	}
+
+	deal(0, k.Unseen)
+
+	return deals
+}
+
+// subsets calls visit with every subset of set with size bones
+func subsets(set models.BoneSet, size int, visit func(models.BoneSet)) {
+	var choose func(rest, chosen models.BoneSet, size int)
+	choose = func(rest, chosen models.BoneSet, size int) {
+		if size == 0 {
+			visit(chosen)
+			return
+		}
+
+		if rest.Len() < size {
+			return
+		}
+
+		bone, without := rest.Pop()
+		choose(without, chosen.Add(bone), size-1)
+		choose(without, chosen, size)
+	}
+
+	choose(set, models.BoneSet{}, size)
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+// Rule of the heuristic strategy that chose a play
+type Rule string
+
+const (
+	// the heaviest bone opens the table
+	RuleOpening Rule = "opening"
+	// no bone glues
+	RulePass Rule = "pass"
+	// only one edge takes bones, the pip the hand has the most of
+	RuleOneSided Rule = "one-sided"
+	// the endgame search solved the hand
+	RuleEndgame Rule = "endgame"
+	// countPlay, an edge is locked
+	RuleCount Rule = "count"
+	// passedPlay, the pip the most opponents passed on
+	RulePassed Rule = "passed"
+	// duoPlay, the heaviest play leaving a pip the partner may follow
+	RuleDuo Rule = "duo"
+	// nothing chose, a pass
+	RuleNone Rule = "none"
+)
+
+// MoveExplanation is what the heuristics saw in a legal play
+type MoveExplanation struct {
+	Play models.DominoPlayWithPass
+	// Sum of the pips, duoPlay plays the heaviest
+	Sum int
+	// Locks is whether countPlay keeps the other edge locked with it
+	Locks bool
+	// Partner is whether the partner may follow on the pip it leaves open
+	Partner bool
+	// Passes are the opponents that passed on the pip it leaves open
+	Passes  int
+	Reasons []string
+}
+
+// Explanation of the play of the heuristic strategy
+type Explanation struct {
+	Moves []MoveExplanation
+	Play  models.DominoPlayWithPass
+	Rule  Rule
+}
+
+// Explain tells what the heuristics saw in every legal play and the rule
+// that chose the play of the heuristic strategy, without the guess tree
+func (g *Session) Explain(ctx context.Context, state *models.DominoGameState) Explanation {
+	if !g.lockPlay(ctx) {
+		return Explanation{Play: g.busyPlay(state), Rule: RuleNone}
+	}
+	defer g.unlockPlay()
+
+	g.observe(state)
+
+	if len(state.Plays) == 0 {
+		explanation := Explanation{Play: g.initialPlay(state), Rule: RuleOpening}
+		for _, play := range state.LegalPlays() {
+			explanation.Moves = append(explanation.Moves, MoveExplanation{
+				Play:    play,
+				Sum:     play.Bone.Sum(),
+				Reasons: []string{fmt.Sprintf("sum %d", play.Bone.Sum())},
+			})
+		}
+
+		return explanation
+	}
+
+	play, rule := g.decide(ctx, state)
+	explanation := Explanation{Play: play, Rule: rule}
+
+	left, right := g.handCanPlayThisTurn(state)
+	leftLocked, rightLocked := lockedEdges(state, left, right)
+	canPlayBoth := len(left) > 0 && len(right) > 0
+
+	duo := g.getDuo()
+	explain := func(bone models.DominoInTable, locks bool, other models.Edge) {
+		move := MoveExplanation{
+			Play: g.playFromDominoInTable(bone),
+			Sum:  bone.Sum(),
+		}
+
+		pip := bone.GlueableSide()
+		if canPlayBoth && locks {
+			move.Locks = true
+			move.Reasons = append(move.Reasons, fmt.Sprintf("keeps the %s edge locked", other))
+		}
+
+		g.UnavailableBonesMutex.Lock()
+		move.Partner = !g.UnavailableBones[duo][pip]
+		move.Passes = g.countPasses(bone)
+		g.UnavailableBonesMutex.Unlock()
+
+		if move.Partner {
+			move.Reasons = append(move.Reasons, fmt.Sprintf("partner may follow on %d", pip))
+		} else {
+			move.Reasons = append(move.Reasons, fmt.Sprintf("partner passed on %d", pip))
+		}
+
+		if move.Passes > 0 {
+			move.Reasons = append(move.Reasons, fmt.Sprintf("%d opponents passed on %d", move.Passes, pip))
+		}
+
+		move.Reasons = append(move.Reasons, fmt.Sprintf("sum %d", move.Sum))
+		explanation.Moves = append(explanation.Moves, move)
+	}
+
+	for _, bone := range left {
+		explain(bone, rightLocked, models.RightEdge)
+	}
+
+	for _, bone := range right {
+		explain(bone, leftLocked, models.LeftEdge)
+	}
+
+	return explanation
+}
@@ -1,172 +1,128 @@
 package game
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"sort"
 	"sync"
+	"time"
 
+	"github.com/josecleiton/domino/app/inference"
 	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
 )
 
-type gameGlobals struct {
+type Session struct {
 	Hand             []models.Domino
-	States           []models.DominoGameState
 	Player           models.PlayerPosition
 	UnavailableBones models.UnavailableBonesPlayer
+	// Opponent is how the opponents play, when the session knows who it faces
+	Opponent *profile.Profile
+
+	tree       *guessTree
+	strategies map[string]Strategy
+	lastUsed   time.Time
 
 	// sync
+	// playing holds a token while a play is chosen, a channel so a request
+	// stops waiting for it at its deadline
+	playing               chan struct{}
 	UnavailableBonesMutex sync.Mutex
-	IntermediateStateWg   sync.WaitGroup
+	treeGeneratingWg      sync.WaitGroup
 }
 
-var g *gameGlobals
-
-func init() {
-	g = &gameGlobals{
-		Hand: []models.Domino{},
+func NewSession() *Session {
+	return &Session{
+		Hand:    []models.Domino{},
+		playing: make(chan struct{}, 1),
 	}
-
 }
 
-func Play(state *models.DominoGameState) models.DominoPlayWithPass {
-	hasToInitialize := false
-	if g.Player != state.PlayerPosition {
-		hasToInitialize = true
-	}
+func Play(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	return DefaultSessions.Session("", state).Play(ctx, state)
+}
 
-	if !hasToInitialize {
-		statesLen := len(g.States)
-		if statesLen > 0 && len(state.Plays) > len(g.States[statesLen-1].Plays) {
-			hasToInitialize = true
-		}
-	}
+func (g *Session) Play(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	return g.PlayStrategy(ctx, DefaultStrategy, state)
+}
 
-	if hasToInitialize {
-		initialize(state)
-	} else {
-		g.States = append(g.States, *state)
+// PlayStrategy chooses a play with the named strategy, searches stop at the
+// deadline of ctx with the best play found so far
+func (g *Session) PlayStrategy(
+	ctx context.Context,
+	name string,
+	state *models.DominoGameState,
+) models.DominoPlayWithPass {
+	if !g.lockPlay(ctx) {
+		return g.busyPlay(state)
 	}
+	defer g.unlockPlay()
 
-	// forLimit := len(g.States) - models.DominoMaxPlayer - 1
-	// if forLimit > 0 {
-	// 	g.States = g.States[forLimit:]
-	// }
+	g.observe(state)
 
-	g.Hand = make([]models.Domino, 0, len(state.Hand))
-	g.Hand = append(g.Hand, state.Hand...)
-	sort.Slice(g.Hand, func(i, j int) bool {
-		return g.Hand[i].Sum() >= g.Hand[j].Sum()
-	})
-
-	if len(state.Plays) > 0 {
-		g.IntermediateStateWg.Add(1)
-		go func() {
-			defer g.IntermediateStateWg.Done()
-			intermediateStates(state)
-		}()
+	return g.strategy(name).Choose(ctx, state)
+}
 
-		return midgamePlay(state)
+// lockPlay takes the play of the session, false when ctx ends first: a
+// strategy PlayGuarded stopped waiting for may still be holding it
+func (g *Session) lockPlay(ctx context.Context) bool {
+	select {
+	case g.playing <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
 	}
-
-	return initialPlay(state)
 }
 
-func intermediateStates(state *models.DominoGameState) {
-	statesLen := len(g.States)
-	last := &g.States[statesLen-1]
-	for i := statesLen - 2; i >= 0; i++ {
-		first := &g.States[i]
-
-		lastPlaysLen := len(last.Plays)
-		if lastPlaysLen == 0 || lastPlaysLen == len(first.Plays) {
-			continue
-		}
+func (g *Session) unlockPlay() {
+	<-g.playing
+}
 
-		stPlayIdx := -1
-		for i := len(first.Plays) - 1; i >= 0; i-- {
-			if first.Plays[i] == last.Plays[lastPlaysLen-1] {
-				stPlayIdx = i
-				break
-			}
+// busyPlay answers the state while another play holds the session
+func (g *Session) busyPlay(state *models.DominoGameState) models.DominoPlayWithPass {
+	play := fallbackPlay(state)
+	log.Printf("Session busy past the deadline, playing %v instead\n", play)
 
-		}
+	return play
+}
 
-		if stPlayIdx == -1 {
-			log.Println("Something went wrong, no play found")
-			continue
-		}
+// observe takes the hand and every pass from the state alone, so a session
+// that missed requests knows as much as one that saw them all
+func (g *Session) observe(state *models.DominoGameState) {
+	g.Player = state.PlayerPosition
 
-		fmt.Println("ndPlay", last.Plays)
-		fmt.Println("stPlay", last.Plays[stPlayIdx])
+	g.UnavailableBonesMutex.Lock()
+	g.UnavailableBones = inference.UnavailableBones(state)
+	g.UnavailableBonesMutex.Unlock()
 
-		nonEvaluatedPlays := last.Plays[stPlayIdx+1:]
-		nonEvaluatedPlaysLen := len(nonEvaluatedPlays)
-		if nonEvaluatedPlaysLen == models.DominoMaxPlayer {
-			continue
-		}
+	g.Hand = make([]models.Domino, 0, len(state.Hand))
+	g.Hand = append(g.Hand, state.Hand...)
+	sort.Slice(g.Hand, func(i, j int) bool {
+		return g.Hand[i].Sum() >= g.Hand[j].Sum()
+	})
+}
 
-		firstPlayer := nonEvaluatedPlays[0].PlayerPosition
-
-		for i := 0; i < models.DominoMaxPlayer; i++ {
-			currentPlayer := firstPlayer.Add(i)
-
-			if i < nonEvaluatedPlaysLen && nonEvaluatedPlays[i].PlayerPosition == currentPlayer {
-				continue
-			}
-
-			playerPassed := currentPlayer
-
-			if _, ok := g.UnavailableBones[playerPassed]; !ok {
-				g.UnavailableBones[playerPassed] = make(
-					models.TableBone,
-					models.DominoUniqueBones,
-				)
-			}
-
-			currentPlayOnEdgeIdx := min(nonEvaluatedPlaysLen-1, i) + stPlayIdx + 1
-			currentPlayOnEdge := first.Plays[currentPlayOnEdgeIdx]
-			var playOnReversedEdge *models.DominoPlay
-			for i := currentPlayOnEdgeIdx - 1; i >= 0; i-- {
-				play := &first.Plays[i]
-				if play.Bone.Edge == currentPlayOnEdge.Bone.Edge {
-					continue
-				}
-				playOnReversedEdge = play
-				break
-			}
-
-			fmt.Println(currentPlayOnEdge)
-			fmt.Println(playOnReversedEdge)
-			fmt.Println(g.UnavailableBones[playerPassed])
-			g.UnavailableBones[playerPassed][currentPlayOnEdge.Bone.GlueableSide()] = true
-			g.UnavailableBones[playerPassed][playOnReversedEdge.Bone.GlueableSide()] = true
-		}
+func (g *Session) strategy(name string) Strategy {
+	if strategy, ok := g.strategies[name]; ok {
+		return strategy
 	}
 
-	g.States = g.States[statesLen-1:]
-}
-
-func initialize(state *models.DominoGameState) {
-	g.Player = state.PlayerPosition
+	factory, ok := strategies[name]
+	if !ok {
+		log.Printf("Unknown strategy %q, using %q\n", name, DefaultStrategy)
+		return g.strategy(DefaultStrategy)
+	}
 
-	g.UnavailableBones = make(
-		models.UnavailableBonesPlayer,
-		models.DominoMaxPlayer,
-	)
-	for i := models.DominoMinPlayer; i <= models.DominoMaxPlayer; i++ {
-		player := models.PlayerPosition(i)
-		g.UnavailableBones[player] = make(
-			models.TableBone,
-			models.DominoUniqueBones,
-		)
+	if g.strategies == nil {
+		g.strategies = make(map[string]Strategy, 1)
 	}
 
-	g.States = make([]models.DominoGameState, 0, models.DominoLength)
-	g.States = append(g.States, *state)
+	g.strategies[name] = factory(g)
+
+	return g.strategies[name]
 }
 
-func initialPlay(state *models.DominoGameState) models.DominoPlayWithPass {
+func (g *Session) initialPlay(state *models.DominoGameState) models.DominoPlayWithPass {
 	return models.DominoPlayWithPass{
 		PlayerPosition: state.PlayerPosition,
 		Bone: &models.DominoInTable{
@@ -179,44 +135,69 @@ func initialPlay(state *models.DominoGameState) models.DominoPlayWithPass {
 	}
 }
 
-func midgamePlay(state *models.DominoGameState) models.DominoPlayWithPass {
-	left, right := handCanPlayThisTurn(state)
-	leftLen, rightLen := len(left), len(right)
+func (g *Session) midgamePlay(
+	ctx context.Context,
+	state *models.DominoGameState,
+) models.DominoPlayWithPass {
+	play, rule := g.decide(ctx, state)
 
-	// pass
-	if leftLen == 0 && rightLen == 0 {
-		edges := state.Edges()
-		if ep, ok := edges[models.LeftEdge]; ok && ep != nil {
-			g.UnavailableBones[g.Player][ep.R] = true
-		}
+	switch rule {
+	case RuleNone:
+		return play
+	case RulePass:
+	default:
+		g.generateTreeByPlay(ctx, state, &play)
+		return play
+	}
 
-		if ep, ok := edges[models.RightEdge]; ok && ep != nil {
-			g.UnavailableBones[g.Player][ep.R] = true
-		}
+	edges := state.Edges()
+	g.UnavailableBonesMutex.Lock()
+	if ep, ok := edges[models.LeftEdge]; ok && ep != nil {
+		g.UnavailableBones[g.Player][ep.R] = true
+	}
 
-		allPlays := make([]models.DominoPlay, 0, len(state.Plays))
-		allPlays = append(allPlays, state.Plays...)
+	if ep, ok := edges[models.RightEdge]; ok && ep != nil {
+		g.UnavailableBones[g.Player][ep.R] = true
+	}
+	g.UnavailableBonesMutex.Unlock()
+
+	allPlays := make([]models.DominoPlay, 0, len(state.Plays))
+	allPlays = append(allPlays, state.Plays...)
 
-		generateTree(state, guessTreeGenerate{
-			Player: g.Player,
-			Hand:   g.Hand,
-			Plays:  allPlays,
-		})
+	g.generateTree(ctx, state, guessTreeGenerate{
+		Player: g.Player,
+		Hand:   g.Hand,
+		Plays:  allPlays,
+	})
 
-		return models.DominoPlayWithPass{PlayerPosition: g.Player}
+	return play
+}
+
+// decide is the play of the heuristic pipeline and the rule that chose it
+func (g *Session) decide(
+	ctx context.Context,
+	state *models.DominoGameState,
+) (models.DominoPlayWithPass, Rule) {
+	left, right := g.handCanPlayThisTurn(state)
+	leftLen, rightLen := len(left), len(right)
+
+	// pass
+	if leftLen == 0 && rightLen == 0 {
+		return models.DominoPlayWithPass{PlayerPosition: g.Player}, RulePass
 	}
 
 	canPlayBoth := leftLen > 0 && rightLen > 0
 	if !canPlayBoth {
-		play := oneSidedPlay(left, right)
-		generateTreeByPlay(state, &play)
-		return play
+		return g.oneSidedPlay(left, right), RuleOneSided
+	}
+
+	if solved := g.maximizeWinningChancesPlay(ctx, state); solved != nil {
+		return *solved, RuleEndgame
 	}
 
-	countResult := countPlay(state, left, right)
+	countResult := g.countPlay(state, left, right)
 	if countResult != nil {
-		generateTreeByPlay(state, countResult)
-		return *countResult
+		return *countResult, RuleCount
 	}
 
 	var duoResult, passedResult *models.DominoPlayWithPass
@@ -227,26 +208,19 @@ func midgamePlay(state *models.DominoGameState) models.DominoPlayWithPass {
 	go func() {
 		defer wg.Done()
 
-		g.IntermediateStateWg.Wait()
-		duoResult = duoPlay(state, left, right)
+		duoResult = g.duoPlay(state, left, right)
 	}()
 
 	go func() {
 		defer wg.Done()
 
-		g.IntermediateStateWg.Wait()
-
-		passedResult = passedPlay(state, left, right)
+		passedResult = g.passedPlay(state, left, right)
 	}()
 
 	wg.Wait()
 
 	if duoResult != nil && passedResult != nil {
-		// if maximizedPlay := maximizeWinningChancesPlay(duoResult, passedResult); maximizedPlay != nil {
-		// 	return *maximizedPlay
-		// }
-
-		passes := countPasses(*passedResult.Bone)
+		passes := g.countPasses(*passedResult.Bone)
 
 		otherEdge := new(models.DominoInTable)
 		if passedResult.Bone.Edge == models.LeftEdge {
@@ -255,41 +229,33 @@ func midgamePlay(state *models.DominoGameState) models.DominoPlayWithPass {
 			*otherEdge = dominoInTableFromEdge(state, models.LeftEdge)
 		}
 
-		play := duoResult
-
-		duoCanPlayOtherEdge := duoCanPlayEdge(state, *otherEdge)
+		duoCanPlayOtherEdge := g.duoCanPlayEdge(state, *otherEdge)
 		if passes > 1 || duoCanPlayOtherEdge {
-			play = passedResult
+			return *passedResult, RulePassed
 		}
 
-		generateTreeByPlay(state, play)
-
-		return *play
+		return *duoResult, RuleDuo
 	}
 
-	possiblePlays := []*models.DominoPlayWithPass{passedResult, duoResult}
-
-	for _, p := range possiblePlays {
-		if p == nil {
-			continue
-		}
+	if passedResult != nil {
+		return *passedResult, RulePassed
+	}
 
-		generateTreeByPlay(state, p)
-		return *p
+	if duoResult != nil {
+		return *duoResult, RuleDuo
 	}
 
 	log.Println("Something went wrong, no play found")
-	return models.DominoPlayWithPass{PlayerPosition: g.Player}
-
+	return models.DominoPlayWithPass{PlayerPosition: g.Player}, RuleNone
 }
 
-func duoCanPlayEdges(state *models.DominoGameState) (bool, bool) {
+func (g *Session) duoCanPlayEdges(state *models.DominoGameState) (bool, bool) {
 	g.UnavailableBonesMutex.Lock()
 	defer g.UnavailableBonesMutex.Unlock()
 
 	leftEdge, rightEdge := dominoInTableFromEdge(state, models.LeftEdge),
 		dominoInTableFromEdge(state, models.RightEdge)
 
-	return duoCanPlayEdge(state, leftEdge),
-		duoCanPlayEdge(state, rightEdge)
+	return g.duoCanPlayEdge(state, leftEdge),
+		g.duoCanPlayEdge(state, rightEdge)
 }
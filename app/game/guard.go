@@ -0,0 +1,112 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+)
+
+// GuardGrace is how long after the deadline PlayGuarded waits for the
+// strategy before answering without it
+var GuardGrace = 200 * time.Millisecond
+
+// PlayGuarded is PlayStrategy answering a legal play whatever the strategy
+// does: if it panics, misses the deadline of ctx or plays something illegal,
+// the fallback is played instead
+func (g *Session) PlayGuarded(
+	ctx context.Context,
+	name string,
+	state *models.DominoGameState,
+) models.DominoPlayWithPass {
+	return g.guard(ctx, name, state, func(ctx context.Context) models.DominoPlayWithPass {
+		return g.PlayStrategy(ctx, name, state)
+	})
+}
+
+// PlayGuardedAgainst is PlayGuarded expecting the opponents to play like
+// opponent, set under the same deadline as the play
+func (g *Session) PlayGuardedAgainst(
+	ctx context.Context,
+	name string,
+	state *models.DominoGameState,
+	opponent *profile.Profile,
+) models.DominoPlayWithPass {
+	return g.guard(ctx, name, state, func(ctx context.Context) models.DominoPlayWithPass {
+		return g.playAgainst(ctx, name, state, opponent)
+	})
+}
+
+func (g *Session) guard(
+	ctx context.Context,
+	name string,
+	state *models.DominoGameState,
+	play func(ctx context.Context) models.DominoPlayWithPass,
+) models.DominoPlayWithPass {
+	// without a deadline a strategy that hangs would hold the session for
+	// good, the next request waits for it no longer than PlayTimeout
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, PlayTimeout)
+		defer cancel()
+	}
+
+	type result struct {
+		play models.DominoPlayWithPass
+		err  error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				results <- result{err: fmt.Errorf("strategy %s panicked: %v", name, r)}
+			}
+		}()
+
+		results <- result{play: play(ctx)}
+	}()
+
+	deadline, _ := ctx.Deadline()
+
+	var r result
+	select {
+	case r = <-results:
+	case <-time.After(time.Until(deadline) + GuardGrace):
+		r.err = fmt.Errorf("strategy %s missed the deadline", name)
+	}
+
+	if r.err == nil {
+		r.play.PlayerPosition = state.PlayerPosition
+		r.err = state.CheckPlay(r.play)
+	}
+
+	if r.err != nil {
+		play := fallbackPlay(state)
+		log.Printf("Guard: %s, playing %v instead\n", r.err, play)
+
+		return play
+	}
+
+	return r.play
+}
+
+// fallbackPlay is the legal play of the heaviest bone, a pass without one
+func fallbackPlay(state *models.DominoGameState) models.DominoPlayWithPass {
+	plays := state.LegalPlays()
+	if len(plays) == 0 {
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	}
+
+	best := plays[0]
+	for _, play := range plays[1:] {
+		if play.Bone.Sum() > best.Bone.Sum() {
+			best = play
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+// handKnowledge is what a seat knows about the hidden hands: the bones it
+// can't see, how many bones every other seat holds and the pips they lack
+type handKnowledge struct {
+	Player      models.PlayerPosition
+	Hand        models.BoneSet
+	Unseen      models.BoneSet
+	HandSizes   [models.DominoMaxPlayer]int
+	Unavailable [models.DominoMaxPlayer]models.PipSet
+}
+
+func newHandKnowledge(
+	state *models.DominoGameState,
+	unavailable models.UnavailableBonesPlayer,
+) handKnowledge {
+	k := handKnowledge{
+		Player:      state.PlayerPosition,
+		Hand:        models.BoneSetOf(state.Hand...),
+		Unavailable: unavailable.PipSets(),
+	}
+
+	for i, pips := range inference.Unavailable(inference.History(state)) {
+		k.Unavailable[i] |= pips
+	}
+
+	set := state.Set()
+	for i := range k.HandSizes {
+		k.HandSizes[i] = set.HandLength()
+	}
+
+	seen := k.Hand
+	for _, play := range state.Plays {
+		seen = seen.Add(play.Bone.Domino)
+		k.HandSizes[play.PlayerPosition-models.DominoMinPlayer]--
+	}
+
+	k.HandSizes[state.PlayerPosition-models.DominoMinPlayer] = len(state.Hand)
+	k.Unseen = set.Bones().Minus(seen)
+
+	return k
+}
+
+// holdable is the unseen bones player may have
+func (k handKnowledge) holdable(player models.PlayerPosition) models.BoneSet {
+	return k.Unseen.Avoiding(k.Unavailable[player-models.DominoMinPlayer])
+}
@@ -2,11 +2,11 @@ package game
 
 import "github.com/josecleiton/domino/app/models"
 
-func getDuo() models.PlayerPosition {
+func (g *Session) getDuo() models.PlayerPosition {
 	return g.Player.Add(2)
 }
 
-func handCanPlayThisTurn(
+func (g *Session) handCanPlayThisTurn(
 	state *models.DominoGameState,
 ) ([]models.DominoInTable, []models.DominoInTable) {
 	bonesGlueLeft := make([]models.DominoInTable, 0, len(g.Hand))
@@ -33,10 +33,10 @@ func handCanPlayThisTurn(
 	return bonesGlueLeft, bonesGlueRight
 }
 
-func duoCanPlayWithBoneGlue(
+func (g *Session) duoCanPlayWithBoneGlue(
 	left, right []models.DominoInTable,
 ) ([]models.DominoInTable, []models.DominoInTable) {
-	duo := getDuo()
+	duo := g.getDuo()
 
 	duoLeft := make([]models.DominoInTable, 0, len(left))
 	duoRight := make([]models.DominoInTable, 0, len(right))
@@ -63,11 +63,11 @@ func duoCanPlayWithBoneGlue(
 	return duoLeft, duoRight
 }
 
-func duoCanPlayEdge(
+func (g *Session) duoCanPlayEdge(
 	state *models.DominoGameState,
 	edge models.DominoInTable,
 ) bool {
-	duo := getDuo()
+	duo := g.getDuo()
 	if v, ok := g.UnavailableBones[duo][edge.GlueableSide()]; ok && v {
 		return false
 	}
@@ -83,13 +83,13 @@ func countBones(state *models.DominoGameState, bone models.DominoInTable) int {
 	return 0
 }
 
-func countPasses(bone models.DominoInTable) int {
+func (g *Session) countPasses(bone models.DominoInTable) int {
 	firstPlayer := g.Player
 	passes := 0
 
 	for i := 0; i < models.DominoMaxPlayer; i++ {
 		currentPlayer := firstPlayer.Add(i)
-		if currentPlayer == getDuo() || currentPlayer == g.Player {
+		if currentPlayer == g.getDuo() || currentPlayer == g.Player {
 			continue
 		}
 
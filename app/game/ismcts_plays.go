@@ -0,0 +1,186 @@
+package game
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+const ISMCTSStrategy = "ismcts"
+
+const (
+	// used when there's no deadline, otherwise it thinks until the deadline
+	ismctsBudget        = 2 * time.Second
+	ismctsMaxIterations = 1 << 18
+	ismctsExploration   = 0.7
+)
+
+// ismctsStrategy is a single observer Information Set MCTS: every iteration
+// deals the hidden bones again, consistent with the passes, and walks one
+// tree shared by all the deals, so the statistics are over what the seat
+// knows, not over a guessed deal
+type ismctsStrategy struct {
+	g *Session
+}
+
+type ismctsNode struct {
+	Move playoutMove
+	Pass bool
+	// Player made the move that leads to this node
+	Player    models.PlayerPosition
+	Parent    *ismctsNode
+	Children  []*ismctsNode
+	Visits    int
+	Available int
+	// Reward is summed from the point of view of Player's team
+	Reward float64
+}
+
+func init() {
+	RegisterStrategy(ISMCTSStrategy, func(session *Session) Strategy {
+		return ismctsStrategy{session}
+	})
+}
+
+func (s ismctsStrategy) Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	plays := state.LegalPlays()
+	switch len(plays) {
+	case 0:
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	case 1:
+		return plays[0]
+	}
+
+	beliefs := s.g.beliefs(state)
+	profiles := s.g.opponentProfiles(state.PlayerPosition)
+	rng := stateRand(state)
+
+	root := &ismctsNode{Player: state.PlayerPosition.Prev()}
+	buf := make([]playoutMove, 0, models.DominoHandLength*models.DominoMaxEdges)
+	deadline := time.Now().Add(ismctsBudget)
+	if d, ok := ctx.Deadline(); ok {
+		deadline = d
+	}
+
+	for i := 0; i < ismctsMaxIterations && time.Now().Before(deadline) && ctx.Err() == nil; i++ {
+		p := newPlayout(state, beliefs.Sample(rng))
+		p.Profiles = profiles
+		node := root
+
+		// selection and expansion
+		for !p.over() {
+			buf = p.moves(buf)
+			if len(buf) == 0 {
+				node = node.passChild(p.Player)
+				p.pass()
+				continue
+			}
+
+			untried := 0
+			for _, move := range buf {
+				if child := node.child(move); child != nil {
+					child.Available++
+					continue
+				}
+
+				buf[untried] = move
+				untried++
+			}
+
+			if untried > 0 {
+				move := buf[rng.Intn(untried)]
+				node = node.addChild(move, p.Player)
+				p.play(move)
+				break
+			}
+
+			node = node.selectChild(buf)
+			p.play(node.Move)
+		}
+
+		p.rollout(rng, buf)
+
+		for ; node != nil; node = node.Parent {
+			node.Visits++
+			node.Reward += p.score(node.Player)
+		}
+	}
+
+	var best *ismctsNode
+	for _, child := range root.Children {
+		if best == nil || child.Visits > best.Visits {
+			best = child
+		}
+	}
+
+	// no iteration ran, ctx was done before the search started
+	if best == nil {
+		heaviest := plays[0]
+		for _, play := range plays[1:] {
+			if play.Bone.Sum() > heaviest.Bone.Sum() {
+				heaviest = play
+			}
+		}
+
+		return heaviest
+	}
+
+	return best.Move.play(state.PlayerPosition)
+}
+
+func (n *ismctsNode) child(move playoutMove) *ismctsNode {
+	for _, child := range n.Children {
+		if !child.Pass && child.Move.Edge == move.Edge && child.Move.Bone.Equals(move.Bone) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func (n *ismctsNode) addChild(move playoutMove, player models.PlayerPosition) *ismctsNode {
+	child := &ismctsNode{
+		Move:      move,
+		Player:    player,
+		Parent:    n,
+		Available: 1,
+	}
+	n.Children = append(n.Children, child)
+
+	return child
+}
+
+// passChild is the only child of a node where the player can't play
+func (n *ismctsNode) passChild(player models.PlayerPosition) *ismctsNode {
+	for _, child := range n.Children {
+		if child.Pass {
+			return child
+		}
+	}
+
+	child := &ismctsNode{Pass: true, Player: player, Parent: n}
+	n.Children = append(n.Children, child)
+
+	return child
+}
+
+// selectChild picks by UCB among the children legal in this deal, using how
+// often each was available instead of the parent visits
+func (n *ismctsNode) selectChild(moves []playoutMove) *ismctsNode {
+	var best *ismctsNode
+	bestValue := math.Inf(-1)
+
+	for _, move := range moves {
+		child := n.child(move)
+
+		value := child.Reward/float64(child.Visits) +
+			ismctsExploration*math.Sqrt(math.Log(float64(child.Available))/float64(child.Visits))
+		if value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"context"
+
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+)
+
+// SetOpponent makes the search strategies expect the opponents to play like
+// the profile, nil forgets it
+func (g *Session) SetOpponent(opponent *profile.Profile) {
+	g.lockPlay(context.Background())
+	defer g.unlockPlay()
+
+	g.Opponent = opponent
+}
+
+// playAgainst is PlayStrategy expecting the opponents to play like
+// opponent, set once the play of the session is taken
+func (g *Session) playAgainst(
+	ctx context.Context,
+	name string,
+	state *models.DominoGameState,
+	opponent *profile.Profile,
+) models.DominoPlayWithPass {
+	if !g.lockPlay(ctx) {
+		return g.busyPlay(state)
+	}
+	defer g.unlockPlay()
+
+	g.Opponent = opponent
+	g.observe(state)
+
+	return g.strategy(name).Choose(ctx, state)
+}
+
+// opponentProfiles has the profile of the opponent in its seats, nil when
+// the session doesn't know who it faces
+func (g *Session) opponentProfiles(player models.PlayerPosition) *[models.DominoMaxPlayer]*profile.Profile {
+	if g.Opponent == nil {
+		return nil
+	}
+
+	var profiles [models.DominoMaxPlayer]*profile.Profile
+	for _, opponent := range []models.PlayerPosition{player.Add(1), player.Add(3)} {
+		profiles[opponent-models.DominoMinPlayer] = g.Opponent
+	}
+
+	return &profiles
+}
+
+// beliefs of the player to move, the opponents' plays weighted by their
+// profile when there's one
+func (g *Session) beliefs(state *models.DominoGameState) *inference.Beliefs {
+	var policies [models.DominoMaxPlayer]inference.Policy
+	if profiles := g.opponentProfiles(state.PlayerPosition); profiles != nil {
+		for i, opponent := range profiles {
+			if opponent != nil {
+				policies[i] = opponent
+			}
+		}
+	}
+
+	return inference.InferWith(state, g.unavailableBones(), policies)
+}
+
+// situation of the player to move as far as a playout knows it
+func (p *playout) situation() profile.Situation {
+	return profile.Situation{
+		Player: p.Player,
+		Hand:   p.Hands[p.Player-models.DominoMinPlayer],
+		Left:   p.Left,
+		Right:  p.Right,
+		Empty:  p.Empty,
+		// past the opening and the early plays
+		Turn:       models.DominoHandLength,
+		PartnerPip: -1,
+	}
+}
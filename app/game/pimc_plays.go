@@ -0,0 +1,97 @@
+package game
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+const PIMCStrategy = "pimc"
+
+const (
+	pimcBudget     = 500 * time.Millisecond
+	pimcMaxSamples = 256
+	pimcRollouts   = 4
+)
+
+// pimcStrategy is a determinized Monte Carlo search: it deals the hidden
+// bones many times, consistent with what's known, plays every candidate out
+// and keeps the one with the best average outcome
+type pimcStrategy struct {
+	g *Session
+}
+
+func init() {
+	RegisterStrategy(PIMCStrategy, func(session *Session) Strategy {
+		return pimcStrategy{session}
+	})
+}
+
+func (s pimcStrategy) Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	plays := state.LegalPlays()
+	switch len(plays) {
+	case 0:
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	case 1:
+		return plays[0]
+	}
+
+	beliefs := s.g.beliefs(state)
+	profiles := s.g.opponentProfiles(state.PlayerPosition)
+	rng := stateRand(state)
+
+	root := newPlayout(state, beliefs.Sample(rng))
+	moves := root.moves(nil)
+	if len(moves) == 1 {
+		return moves[0].play(state.PlayerPosition)
+	}
+
+	scores := make([]float64, len(moves))
+	buf := make([]playoutMove, 0, models.DominoHandLength*models.DominoMaxEdges)
+	deadline := searchDeadline(ctx, pimcBudget)
+
+	for i := 0; i < pimcMaxSamples && time.Now().Before(deadline) && ctx.Err() == nil; i++ {
+		determinized := newPlayout(state, beliefs.Sample(rng))
+		determinized.Profiles = profiles
+
+		for j, move := range moves {
+			for r := 0; r < pimcRollouts; r++ {
+				p := determinized
+				p.play(move)
+				p.rollout(rng, buf)
+
+				scores[j] += p.score(state.PlayerPosition)
+			}
+		}
+	}
+
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	return moves[best].play(state.PlayerPosition)
+}
+
+// unavailableBones copies what the session inferred from the passes
+func (g *Session) unavailableBones() models.UnavailableBonesPlayer {
+	g.UnavailableBonesMutex.Lock()
+	defer g.UnavailableBonesMutex.Unlock()
+
+	return g.UnavailableBones.Copy()
+}
+
+// stateRand is seeded by the deal and turn, so the same request gets the
+// same answer
+func stateRand(state *models.DominoGameState) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(DealID(state)))
+	h.Write([]byte{byte(len(state.Plays))})
+
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
@@ -0,0 +1,304 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+)
+
+// playout is a compact, perfect information copy of a match used by the
+// search strategies: only the open pips of the table matter from here on
+type playout struct {
+	Hands       [models.DominoMaxPlayer]models.BoneSet
+	Left, Right int
+	Empty       bool
+	Player      models.PlayerPosition
+	Last        models.PlayerPosition
+	Passes      int
+	// Profiles are how the seats that have one play the rollouts
+	Profiles *[models.DominoMaxPlayer]*profile.Profile
+	// Goal of the match, nil when only winning the hand matters
+	Goal *models.Goal
+	// Batida is how Last emptied its hand
+	Batida models.Batida
+}
+
+type playoutMove struct {
+	Bone models.Domino
+	Edge models.Edge
+}
+
+// playoutUndo restores a playout to the state before a play or pass
+type playoutUndo struct {
+	Player      models.PlayerPosition
+	Last        models.PlayerPosition
+	Left, Right int
+	Empty       bool
+	Passes      int
+	// Bone left the hand of Player, zero on passes
+	Bone models.BoneSet
+}
+
+const (
+	playoutWin  = 1.0
+	playoutLoss = 0.0
+)
+
+func newPlayout(
+	state *models.DominoGameState,
+	hands [models.DominoMaxPlayer]models.BoneSet,
+) playout {
+	p := playout{
+		Hands:  hands,
+		Player: state.PlayerPosition,
+		Empty:  len(state.Table) == 0,
+		Goal:   state.Goal,
+	}
+
+	if !p.Empty {
+		p.Left = state.Table[0].L
+		p.Right = state.Table[len(state.Table)-1].R
+	}
+
+	if len(state.Plays) > 0 {
+		p.Last = state.Plays[len(state.Plays)-1].PlayerPosition
+	}
+
+	return p
+}
+
+func (p *playout) hand(player models.PlayerPosition) models.BoneSet {
+	return p.Hands[player-models.DominoMinPlayer]
+}
+
+func (p *playout) moves(moves []playoutMove) []playoutMove {
+	moves = moves[:0]
+
+	hand := p.hand(p.Player)
+	if p.Empty {
+		for rest := hand; !rest.Empty(); {
+			var bone models.Domino
+			bone, rest = rest.Pop()
+			moves = append(moves, playoutMove{Bone: bone, Edge: models.LeftEdge})
+		}
+
+		return moves
+	}
+
+	for rest := hand.Intersect(models.BonesWithPip(p.Left)); !rest.Empty(); {
+		var bone models.Domino
+		bone, rest = rest.Pop()
+		moves = append(moves, playoutMove{Bone: bone, Edge: models.LeftEdge})
+	}
+
+	// same result as the left edge
+	if p.Left == p.Right {
+		return moves
+	}
+
+	for rest := hand.Intersect(models.BonesWithPip(p.Right)); !rest.Empty(); {
+		var bone models.Domino
+		bone, rest = rest.Pop()
+		moves = append(moves, playoutMove{Bone: bone, Edge: models.RightEdge})
+	}
+
+	return moves
+}
+
+func (p *playout) play(move playoutMove) playoutUndo {
+	undo := p.undoPoint()
+
+	idx := p.Player - models.DominoMinPlayer
+	undo.Bone = p.Hands[idx].Intersect(move.Bone.Bit())
+	p.Hands[idx] = p.Hands[idx].Minus(undo.Bone)
+
+	bone := move.Bone
+	if p.Hands[idx].Empty() && !p.Empty {
+		p.Batida = models.BatidaOf(bone, p.Left, p.Right)
+	}
+
+	switch {
+	case p.Empty:
+		p.Left, p.Right, p.Empty = bone.L, bone.R, false
+	case move.Edge == models.LeftEdge:
+		p.Left = otherSide(bone, p.Left)
+	default:
+		p.Right = otherSide(bone, p.Right)
+	}
+
+	p.Last = p.Player
+	p.Passes = 0
+	p.Player = p.Player.Next()
+
+	return undo
+}
+
+func (p *playout) pass() playoutUndo {
+	undo := p.undoPoint()
+
+	p.Passes++
+	p.Player = p.Player.Next()
+
+	return undo
+}
+
+func (p *playout) undoPoint() playoutUndo {
+	return playoutUndo{
+		Player: p.Player,
+		Last:   p.Last,
+		Left:   p.Left,
+		Right:  p.Right,
+		Empty:  p.Empty,
+		Passes: p.Passes,
+	}
+}
+
+func (p *playout) undo(undo playoutUndo) {
+	idx := undo.Player - models.DominoMinPlayer
+	p.Hands[idx] = p.Hands[idx].Union(undo.Bone)
+
+	p.Player, p.Last = undo.Player, undo.Last
+	p.Left, p.Right, p.Empty = undo.Left, undo.Right, undo.Empty
+	p.Passes = undo.Passes
+	// the playout wasn't over before
+	p.Batida = models.BatidaNone
+}
+
+func (p *playout) over() bool {
+	if p.Passes >= models.DominoMaxPlayer {
+		return true
+	}
+
+	return p.Last != 0 && p.hand(p.Last).Empty()
+}
+
+// winner must only be called when the playout is over
+func (p *playout) winner() models.PlayerPosition {
+	if p.hand(p.Last).Empty() {
+		return p.Last
+	}
+
+	sums := [2]int{}
+	for i, hand := range p.Hands {
+		sums[i%2] += hand.Sum()
+	}
+
+	switch {
+	case sums[0] < sums[1]:
+		return models.DominoMinPlayer
+	case sums[0] > sums[1]:
+		return models.DominoMinPlayer + 1
+	}
+
+	// whoever closed the game loses the tie
+	return p.Last.Next()
+}
+
+// score of the outcome for the team of player, how much it's worth in the
+// match when there's a goal
+func (p *playout) score(player models.PlayerPosition) float64 {
+	winner := p.winner()
+	won := sameTeam(winner, player)
+
+	if p.Goal != nil {
+		return p.Goal.Value(won, p.points(winner))
+	}
+
+	if won {
+		return playoutWin
+	}
+
+	return playoutLoss
+}
+
+// points the team of winner scores by the rules of the goal
+func (p *playout) points(winner models.PlayerPosition) int {
+	pips := 0
+	for i, hand := range p.Hands {
+		if !sameTeam(models.PlayerPosition(i+models.DominoMinPlayer), winner) {
+			pips += hand.Sum()
+		}
+	}
+
+	batida := models.BatidaNone
+	if p.hand(p.Last).Empty() {
+		batida = p.Batida
+	}
+
+	return p.Goal.Rules.Points(batida, pips)
+}
+
+// rollout plays the match to the end, mostly dropping the heaviest bone
+func (p *playout) rollout(rng *rand.Rand, buf []playoutMove) {
+	for !p.over() {
+		buf = p.moves(buf)
+		if len(buf) == 0 {
+			p.pass()
+			continue
+		}
+
+		if p.Profiles != nil {
+			if opponent := p.Profiles[p.Player-models.DominoMinPlayer]; opponent != nil {
+				p.play(p.profileMove(opponent, rng, buf))
+				continue
+			}
+		}
+
+		p.play(rolloutMove(rng, buf))
+	}
+}
+
+// profileMove is the move the profile would pick, it only knows the hand
+// and the open pips here
+func (p *playout) profileMove(opponent *profile.Profile, rng *rand.Rand, moves []playoutMove) playoutMove {
+	bone, ok := opponent.Choose(p.situation(), rng)
+	if !ok {
+		return rolloutMove(rng, moves)
+	}
+
+	for _, move := range moves {
+		if move.Bone.Equals(bone) {
+			return move
+		}
+	}
+
+	return rolloutMove(rng, moves)
+}
+
+func rolloutMove(rng *rand.Rand, moves []playoutMove) playoutMove {
+	if len(moves) == 1 || rng.Intn(4) == 0 {
+		return moves[rng.Intn(len(moves))]
+	}
+
+	heaviest := moves[0]
+	for _, move := range moves[1:] {
+		if move.Bone.Sum() > heaviest.Bone.Sum() {
+			heaviest = move
+		}
+	}
+
+	return heaviest
+}
+
+func otherSide(bone models.Domino, side int) int {
+	if bone.L == side {
+		return bone.R
+	}
+
+	return bone.L
+}
+
+func sameTeam(a, b models.PlayerPosition) bool {
+	return a%2 == b%2
+}
+
+func (m playoutMove) play(player models.PlayerPosition) models.DominoPlayWithPass {
+	return models.DominoPlayWithPass{
+		PlayerPosition: player,
+		Bone: &models.DominoInTable{
+			Edge:   m.Edge,
+			Domino: m.Bone,
+		},
+	}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"container/list"
+	"context"
 	"math/rand"
 	"reflect"
 	"sync"
@@ -29,6 +30,11 @@ type guessTree struct {
 	Cursor *guessTreeNode
 	Root   *guessTreeNode
 	Leafs  *list.List
+	// Player the tree guesses for, copied from the session so a later
+	// request doesn't change it under the generation
+	Player models.PlayerPosition
+	// mutex is held by the generation and by every cursor reposition
+	mutex sync.Mutex
 }
 
 type guessTreeGenerate struct {
@@ -48,13 +54,18 @@ type guessTreeGenerateStack struct {
 const startGeneratingTreeDelta = 18
 const firstTreeDepth = 1
 
-var tree *guessTree
-var treeGeneratingWg sync.WaitGroup
+// GuessTree toggles the background guess tree generation, the tree is not
+// used to choose plays
+var GuessTree = true
 
 func WaitTreeGeneration() *guessTree {
-	treeGeneratingWg.Wait()
+	return DefaultSessions.Last().WaitTreeGeneration()
+}
+
+func (g *Session) WaitTreeGeneration() *guessTree {
+	g.treeGeneratingWg.Wait()
 
-	return tree
+	return g.tree
 }
 
 func (s guessTreeGenerateStack) GenerateChildrenPlays(
@@ -149,11 +160,14 @@ func (s guessTreeGenerateStack) GenerateChildrenPlays(
 	return result
 }
 
-func (t guessTree) RepositionCursor(generate guessTreeGenerate) *guessTreeNode {
+func (t *guessTree) RepositionCursor(
+	ctx context.Context,
+	generate guessTreeGenerate,
+) *guessTreeNode {
 	queue := list.New()
 	queue.PushBack(t.Cursor)
 
-	for queue.Len() > 0 {
+	for queue.Len() > 0 && ctx.Err() == nil {
 		e := queue.Front()
 		node := e.Value.(*guessTreeNode)
 
@@ -175,122 +189,8 @@ func (t guessTree) RepositionCursor(generate guessTreeGenerate) *guessTreeNode {
 	return t.Cursor
 }
 
-// func maximizeWinningChancesPlay(
-// 	plays ...*models.DominoPlayWithPass,
-// ) *models.DominoPlayWithPass {
-// 	if tree == nil || len(plays) == 0 {
-// 		return nil
-// 	}
-
-// 	WaitTreeGeneration()
-
-// 	treeTraversingWg := sync.WaitGroup{}
-// 	treeTraversingWg.Add(len(plays))
-
-// 	playWinningTable := make(map[*models.DominoPlayWithPass][]int, len(plays))
-// 	playDrawTable := make(map[*models.DominoPlayWithPass][]int, len(plays))
-
-// 	pathsByPlay := func(play *models.DominoPlayWithPass) {
-// 		defer treeTraversingWg.Done()
-// 		winningLeafDepths := playWinningTable[play]
-// 		drawLeafDepths := playDrawTable[play]
-
-// 		for current := tree.Leafs.Front(); current != nil; current = current.Next() {
-// 			leaf := current.Value.(*guessTreeLeaf)
-// 			deltaDepth := leaf.Depth - tree.Cursor.Depth
-
-// 			if leaf.Draw && leaf.Winner {
-// 				drawLeafDepths = append(
-// 					drawLeafDepths,
-// 					deltaDepth,
-// 				)
-// 				continue
-// 			}
-
-// 			if !leaf.Winner {
-// 				continue
-// 			}
-
-// 			winningLeafDepths = append(
-// 				winningLeafDepths,
-// 				deltaDepth,
-// 			)
-// 		}
-
-// 		sort.Slice(winningLeafDepths, func(i, j int) bool {
-// 			return winningLeafDepths[i] < winningLeafDepths[j]
-// 		})
-
-// 		sort.Slice(drawLeafDepths, func(i, j int) bool {
-// 			return drawLeafDepths[i] < drawLeafDepths[j]
-// 		})
-// 	}
-
-// 	for _, play := range plays {
-// 		playWinningTable[play] = []int{}
-// 		playDrawTable[play] = []int{}
-// 		go pathsByPlay(play)
-// 	}
-
-// 	treeTraversingWg.Wait()
-
-// 	for k, v := range playWinningTable {
-// 		if len(v) > 0 {
-// 			continue
-// 		}
-
-// 		delete(playWinningTable, k)
-// 	}
-
-// 	for k, v := range playDrawTable {
-// 		if len(v) > 0 {
-// 			continue
-// 		}
-// 		delete(playDrawTable, k)
-// 	}
-
-// 	playWinningTableLen, playDrawTableLen :=
-// 		len(playWinningTable), len(playDrawTable)
-// 	if playWinningTableLen == 0 && playDrawTableLen == 0 {
-// 		return nil
-// 	}
-
-// 	if playWinningTableLen == 0 {
-// 		return betterPlayFromPlayDepth(plays, playDrawTable)
-// 	}
-
-// 	return betterPlayFromPlayDepth(plays, playWinningTable)
-// }
-
-// func betterPlayFromPlayDepth(
-// 	plays []*models.DominoPlayWithPass,
-// 	playDepthTable map[*models.DominoPlayWithPass][]int,
-// ) *models.DominoPlayWithPass {
-// 	if len(playDepthTable) == 0 {
-// 		return nil
-// 	}
-
-// 	betterPlay := plays[0]
-// 	for _, play := range plays[1:] {
-// 		if playDepthTable[betterPlay][0] <= 1 {
-// 			return betterPlay
-// 		}
-
-// 		if playDepthTable[play][0] <= 1 {
-// 			return play
-// 		}
-
-// 		if len(playDepthTable[betterPlay]) <= len(playDepthTable[play]) {
-// 			continue
-// 		}
-
-// 		betterPlay = play
-// 	}
-
-// 	return betterPlay
-// }
-
-func generateTreeByPlay(
+func (g *Session) generateTreeByPlay(
+	ctx context.Context,
 	state *models.DominoGameState,
 	play *models.DominoPlayWithPass,
 ) {
@@ -359,7 +259,8 @@ func generateTreeByPlay(
 		})
 	}
 
-	generateTree(
+	g.generateTree(
+		ctx,
 		&models.DominoGameState{
 			PlayerPosition: play.PlayerPosition,
 			Hand:           newHand,
@@ -376,12 +277,27 @@ func generateTreeByPlay(
 	)
 }
 
-func generateTree(state *models.DominoGameState, generate guessTreeGenerate) {
-	treeGeneratingWg.Add(1)
-	if tree != nil {
+// generateTree grows the guess tree in background until it's complete or ctx
+// is done, whatever was generated by then is kept
+func (g *Session) generateTree(
+	ctx context.Context,
+	state *models.DominoGameState,
+	generate guessTreeGenerate,
+) {
+	// the tree expects every bone of double-six dealt
+	if !GuessTree || state.Set() != models.DoubleSix {
+		return
+	}
+
+	if tree := g.tree; tree != nil {
+		g.treeGeneratingWg.Add(1)
 		go func() {
-			defer treeGeneratingWg.Done()
-			tree.Cursor = tree.RepositionCursor(generate)
+			defer g.treeGeneratingWg.Done()
+
+			tree.mutex.Lock()
+			defer tree.mutex.Unlock()
+
+			tree.Cursor = tree.RepositionCursor(ctx, generate)
 		}()
 		return
 	}
@@ -392,8 +308,7 @@ func generateTree(state *models.DominoGameState, generate guessTreeGenerate) {
 	{
 		defer g.UnavailableBonesMutex.Unlock()
 
-		delta := models.DominoLength - len(state.Plays) +
-			len(state.Hand) + len(g.UnavailableBones[state.PlayerPosition.Next()])
+		delta := treeDelta(state, g.UnavailableBones[state.PlayerPosition.Next()])
 		if delta > startGeneratingTreeDelta {
 			return
 		}
@@ -407,60 +322,82 @@ func generateTree(state *models.DominoGameState, generate guessTreeGenerate) {
 	copy(table, state.Table)
 	copy(hand, state.Hand)
 
-	go func() {
-		defer treeGeneratingWg.Done()
-		tree = new(guessTree)
-
-		node := new(guessTreeNode)
-		*node = guessTreeNode{
-			Player:   state.PlayerPosition,
-			Table:    table,
-			Hand:     hand,
-			Depth:    firstTreeDepth,
-			Children: list.New(),
-		}
+	node := new(guessTreeNode)
+	*node = guessTreeNode{
+		Player:   state.PlayerPosition,
+		Table:    table,
+		Hand:     hand,
+		Depth:    firstTreeDepth,
+		Children: list.New(),
+	}
 
-		tree.Root = node
-		tree.Cursor = node
-		tree.Leafs = list.New()
+	tree := &guessTree{
+		Root:   node,
+		Cursor: node,
+		Leafs:  list.New(),
+		Player: g.Player,
+	}
+	g.tree = tree
+
+	// locked before the goroutine starts, the next request repositions the
+	// cursor only once the nodes stop being added
+	tree.mutex.Lock()
+	// added only with a goroutine to call Done, a skipped generation leaves
+	// WaitTreeGeneration nothing to wait for
+	g.treeGeneratingWg.Add(1)
+	go func() {
+		defer g.treeGeneratingWg.Done()
+		defer tree.mutex.Unlock()
 
-		generateTreePlays(&guessTreeGenerateStack{
+		tree.generatePlays(ctx, &guessTreeGenerateStack{
 			generate:         generate,
-			player:           g.Player,
+			player:           tree.Player,
 			unavailableBones: unavailableBonesCopy,
 			node:             node,
 		})
 	}()
 }
 
-func generateTreePlays(init *guessTreeGenerateStack) *guessTree {
+// treeDelta tells how far the game is from a tree small enough to
+// generate. A pip the next player passed on rules out the deals with it in
+// that hand, so it counts as known: adding it, as before the passes were
+// inferred from the plays, kept the tree from ever starting once the next
+// player had passed a few times.
+func treeDelta(state *models.DominoGameState, nextPassed models.TableBone) int {
+	return models.DominoLength - len(state.Plays) + len(state.Hand) - len(nextPassed)
+}
+
+func (t *guessTree) generatePlays(
+	ctx context.Context,
+	init *guessTreeGenerateStack,
+) *guessTree {
 	if init == nil {
-		return tree
+		return t
 	}
 
 	stack := list.New()
 	stack.PushBack(init)
 
-	for stack.Len() > 0 {
+	for stack.Len() > 0 && ctx.Err() == nil {
 		// fmt.Println(stack.Len())
 		element := stack.Back()
 		top := element.Value.(*guessTreeGenerateStack)
 		stack.Remove(element)
 
 		if len(top.node.Table) == models.DominoLength {
-			top.leafPushBack(&guessTreeLeaf{
+			top.leafPushBack(t, &guessTreeLeaf{
 				guessTreeNode: *top.node,
 				Draw:          false,
-				Winner: top.node.Player == g.Player ||
-					top.node.Player == getDuo(),
+				Winner: top.node.Player == t.Player ||
+					top.node.Player == t.Player.Add(2),
 			})
 
 			continue
 		}
 
-		passLeaf := top.leafFromPasses()
+		passLeaf := top.leafFromPasses(t.Player)
 		if passLeaf != nil {
-			top.leafPushBack(passLeaf)
+			top.leafPushBack(t, passLeaf)
 			continue
 		}
 
@@ -557,7 +494,7 @@ func generateTreePlays(init *guessTreeGenerateStack) *guessTree {
 		}
 	}
 
-	return tree
+	return t
 
 }
 
@@ -586,7 +523,7 @@ func (top *guessTreeNode) AddChildren(children *list.List) {
 	}
 }
 
-func (top guessTreeGenerateStack) leafPushBack(leaf *guessTreeLeaf) {
+func (top guessTreeGenerateStack) leafPushBack(tree *guessTree, leaf *guessTreeLeaf) {
 	parent := top.node.Parent
 
 	for current := parent.Children.Front(); current != nil; current = current.Next() {
@@ -607,7 +544,7 @@ func (top guessTreeGenerateStack) leafPushBack(leaf *guessTreeLeaf) {
 	tree.Leafs.PushBack(newLeaf)
 }
 
-func (top guessTreeGenerateStack) leafFromPasses() *guessTreeLeaf {
+func (top guessTreeGenerateStack) leafFromPasses(player models.PlayerPosition) *guessTreeLeaf {
 	aux := top.node
 	passes := 0
 	handSumPlayer := make(map[models.PlayerPosition]int, models.DominoMaxPlayer)
@@ -629,14 +566,14 @@ func (top guessTreeGenerateStack) leafFromPasses() *guessTreeLeaf {
 	}
 
 	winner := false
-	duo := getDuo()
+	duo := player.Add(2)
 
-	currentCoupleSum := handSumPlayer[g.Player] + handSumPlayer[duo]
-	otherCoupleSum := handSumPlayer[g.Player.Next()] + handSumPlayer[duo.Next()]
+	currentCoupleSum := handSumPlayer[player] + handSumPlayer[duo]
+	otherCoupleSum := handSumPlayer[player.Next()] + handSumPlayer[duo.Next()]
 
 	if currentCoupleSum < otherCoupleSum || (currentCoupleSum == otherCoupleSum &&
 		lastBlockedNode != nil &&
-		lastBlockedNode.Player != g.Player &&
+		lastBlockedNode.Player != player &&
 		lastBlockedNode.Player != duo) {
 		winner = true
 	}
@@ -653,44 +590,9 @@ func restingDominoes(
 	player models.PlayerPosition,
 	ub models.UnavailableBonesPlayer,
 ) []models.Domino {
-	cannotPlayMap := make(models.TableMap, models.DominoUniqueBones)
-
-	const maxBone = models.DominoMaxBone
-	for i := models.DominoMinBone; i <= maxBone; i++ {
-		cannotPlayMap[i] = make(models.TableBone, models.DominoUniqueBones)
-	}
+	cannotPlay := models.BoneSetOf(top.node.Table...).Union(top.node.searchAllHandsBones(player))
 
-	for boneSide, ok := range ub[player] {
-		if !ok {
-			continue
-		}
-
-		for i := models.DominoMinBone; i <= models.DominoMaxBone; i++ {
-			cannotPlayMap[boneSide][i] = true
-			cannotPlayMap[i][boneSide] = true
-		}
-	}
-
-	for _, bone := range top.node.Table {
-		cannotPlayMap[bone.L][bone.R] = true
-		cannotPlayMap[bone.R][bone.L] = true
-	}
-
-	for _, bone := range top.node.searchAllHandsDominoes(player) {
-		cannotPlayMap[bone.L][bone.R] = true
-		cannotPlayMap[bone.R][bone.L] = true
-	}
-
-	dominoes := make([]models.Domino, 0, startGeneratingTreeDelta)
-	for i := models.DominoMinBone; i <= maxBone; i++ {
-		for j := i; j <= maxBone; j++ {
-			if cannotPlayMap[i][j] || cannotPlayMap[j][i] {
-				continue
-			}
-
-			dominoes = append(dominoes, models.Domino{L: i, R: j})
-		}
-	}
+	dominoes := models.AllBones.Minus(cannotPlay).Avoiding(ub[player].PipSet()).Dominoes()
 
 	rand.Shuffle(len(dominoes), func(i, j int) {
 		dominoes[i], dominoes[j] = dominoes[j], dominoes[i]
@@ -699,14 +601,14 @@ func restingDominoes(
 	return dominoes
 }
 
-func (top guessTreeNode) searchAllHandsDominoes(
+func (top guessTreeNode) searchAllHandsBones(
 	player models.PlayerPosition,
-) []models.Domino {
-	result := make([]models.Domino, 0, models.DominoHandLength*3)
+) models.BoneSet {
+	var result models.BoneSet
 
 	i := 0
 	for current := &top; current != nil && i <= models.DominoMaxPlayer; current = current.Parent {
-		result = append(result, current.Hand...)
+		result = result.Union(models.BoneSetOf(current.Hand...))
 
 		i++
 	}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+const SessionTTL = 30 * time.Minute
+const MaxSessions = 1024
+
+type Sessions struct {
+	mutex    sync.Mutex
+	sessions map[string]*list.Element
+	// most recently used first
+	recent *list.List
+	last   *Session
+}
+
+type sessionEntry struct {
+	key     string
+	session *Session
+}
+
+var DefaultSessions = NewSessions()
+
+func NewSessions() *Sessions {
+	return &Sessions{
+		sessions: make(map[string]*list.Element),
+		recent:   list.New(),
+	}
+}
+
+// Session returns the memory of the seat that received state in the match
+// matchID. Without a matchID the match is identified by the seat deal.
+func (s *Sessions) Session(matchID string, state *models.DominoGameState) *Session {
+	if matchID == "" {
+		matchID = DealID(state)
+	}
+
+	key := fmt.Sprintf("%s/%d", matchID, state.PlayerPosition)
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for e := s.recent.Back(); e != nil; e = s.recent.Back() {
+		entry := e.Value.(*sessionEntry)
+		if now.Sub(entry.session.lastUsed) <= SessionTTL && s.recent.Len() < MaxSessions {
+			break
+		}
+
+		if entry.key == key {
+			break
+		}
+
+		s.recent.Remove(e)
+		delete(s.sessions, entry.key)
+	}
+
+	var session *Session
+	if e, ok := s.sessions[key]; ok {
+		session = e.Value.(*sessionEntry).session
+		s.recent.MoveToFront(e)
+	} else {
+		session = NewSession()
+		s.sessions[key] = s.recent.PushFront(&sessionEntry{key: key, session: session})
+	}
+
+	session.lastUsed = now
+	s.last = session
+
+	return session
+}
+
+func (s *Sessions) Last() *Session {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.last == nil {
+		return NewSession()
+	}
+
+	return s.last
+}
+
+func (s *Sessions) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.sessions)
+}
+
+// DealID is the seat and the bones it was dealt: the current hand plus every
+// bone it already played. It doesn't change during a match.
+func DealID(state *models.DominoGameState) string {
+	deal := make([]models.Domino, 0, models.DominoHandLength)
+	deal = append(deal, state.Hand...)
+
+	for _, play := range state.Plays {
+		if play.PlayerPosition != state.PlayerPosition {
+			continue
+		}
+
+		deal = append(deal, play.Bone.Domino)
+	}
+
+	bones := make([]string, 0, len(deal))
+	for _, bone := range deal {
+		if bone.L > bone.R {
+			bone = bone.Reversed()
+		}
+
+		bones = append(bones, models.DominoToString(bone))
+	}
+
+	sort.Strings(bones)
+
+	return fmt.Sprintf("%d:%s", state.PlayerPosition, strings.Join(bones, ","))
+}
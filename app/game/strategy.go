@@ -0,0 +1,104 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+// Strategy chooses the play of a session, it must answer by the deadline of
+// ctx, returning the best play found so far
+type Strategy interface {
+	Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass
+}
+
+// StrategyFactory builds the strategy of a session, so it can keep its
+// memory of the match there
+type StrategyFactory func(session *Session) Strategy
+
+const (
+	HeuristicStrategy = "heuristic"
+	GlueStrategy      = "glue"
+)
+
+var strategies = map[string]StrategyFactory{}
+
+// DefaultStrategy is used by Play and Session.Play
+var DefaultStrategy = HeuristicStrategy
+
+// PlayTimeout is the think time of a request, under the 3s of run_domino.js
+// and the 5s of the rules
+var PlayTimeout = 2500 * time.Millisecond
+
+func init() {
+	RegisterStrategy(HeuristicStrategy, func(session *Session) Strategy {
+		return heuristicStrategy{session}
+	})
+
+	RegisterStrategy(GlueStrategy, func(session *Session) Strategy {
+		return glueStrategy{}
+	})
+}
+
+func RegisterStrategy(name string, factory StrategyFactory) {
+	if _, ok := strategies[name]; ok {
+		panic(fmt.Sprintf("game: strategy %q registered twice", name))
+	}
+
+	strategies[name] = factory
+}
+
+func HasStrategy(name string) bool {
+	_, ok := strategies[name]
+	return ok
+}
+
+func Strategies() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// heuristicStrategy is the countPlay -> duoPlay/passedPlay pipeline
+type heuristicStrategy struct {
+	g *Session
+}
+
+func (s heuristicStrategy) Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	if len(state.Plays) > 0 {
+		return s.g.midgamePlay(ctx, state)
+	}
+
+	return s.g.initialPlay(state)
+}
+
+// glueStrategy plays the first bone that glues, like the example bot
+type glueStrategy struct{}
+
+func (glueStrategy) Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	plays := state.LegalPlays()
+	if len(plays) == 0 {
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	}
+
+	return plays[0]
+}
+
+// searchDeadline is when a search with the given budget must stop, at the
+// latest the deadline of ctx
+func searchDeadline(ctx context.Context, budget time.Duration) time.Time {
+	deadline := time.Now().Add(budget)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+
+	return deadline
+}
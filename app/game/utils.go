@@ -11,7 +11,7 @@ type indexedCount struct {
 	Count int
 }
 
-func playFromDominoInTable(bone models.DominoInTable) models.DominoPlayWithPass {
+func (g *Session) playFromDominoInTable(bone models.DominoInTable) models.DominoPlayWithPass {
 	return models.DominoPlayWithPass{
 		PlayerPosition: g.Player,
 		Bone:           &bone,
@@ -45,11 +45,11 @@ func dominoInTableFromDomino(
 	}
 }
 
-func sortByPassed(bones []models.DominoInTable) {
+func (g *Session) sortByPassed(bones []models.DominoInTable) {
 	g.UnavailableBonesMutex.Lock()
 	defer g.UnavailableBonesMutex.Unlock()
 	sort.Slice(bones, func(i, j int) bool {
-		return countPasses(bones[i]) >= countPasses(bones[j])
+		return g.countPasses(bones[i]) >= g.countPasses(bones[j])
 	})
 }
 
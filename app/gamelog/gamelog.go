@@ -0,0 +1,262 @@
+package gamelog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+// Dirs are the logs of run_domino.js shipped with the repo
+var Dirs = []string{"logs-campeonato", "logs-amistoso", "logs-campeonato-desempate"}
+
+var ErrMalformed = errors.New("malformed log")
+
+// Game is a match as narrated by run_domino.js
+type Game struct {
+	Source string
+	// Bots is the name of the bot of each seat
+	Bots [models.DominoMaxPlayer]string
+	Deal referee.Hands
+	// Turns are the answers of the players in order, the bones as the
+	// players sent them. The opening 6-6 is the first one.
+	Turns   []models.DominoPlayWithPass
+	Winner  referee.Team
+	Outcome referee.Outcome
+	// Player who emptied the hand, played the closing bone or got disqualified
+	Player models.PlayerPosition
+	// Err is why Player was disqualified
+	Err error
+	// Points left in each hand when the game closed
+	Points [models.DominoMaxPlayer]int
+}
+
+var (
+	containerRe = regexp.MustCompile(`^Iniciando container do jogador (\d)\.\.\. (.+)$`)
+	dealRe      = regexp.MustCompile(`^\s+Jogador (\d): (.*)$`)
+	boneRe      = regexp.MustCompile(`\[(\d-\d)\]`)
+	openingRe   = regexp.MustCompile(`^Jogador (\d) começa a partida e coloca a pedra \[(\d-\d)\] na mesa\.$`)
+	playRe      = regexp.MustCompile(`^Jogador (\d) jogou a pedra \[(\d-\d)\] no lado (esquerda|direita) da mesa\.$`)
+	passRe      = regexp.MustCompile(`^Jogador (\d) passou a vez\.$`)
+	dominoRe    = regexp.MustCompile(`^Jogador (\d) ganhou a partida!$`)
+	closedRe    = regexp.MustCompile(`^Todos os jogadores passaram a vez`)
+	pointsRe    = regexp.MustCompile(`^\s+Jogador (\d): (\d+) pontos\.$`)
+	teamWinRe   = regexp.MustCompile(`^Jogadores (\d) e \d ganharam com`)
+	tieRe       = regexp.MustCompile(`^As duas equipes tem a mesma quantidade de pontos\. Jogador (\d) foi`)
+	winnerRe    = regexp.MustCompile(`^(?:Partida \d+: )?Vencedor: bot\d\.$`)
+)
+
+// disqualifications maps the narration of run_domino.js to the referee
+// errors
+var disqualifications = []struct {
+	re  *regexp.Regexp
+	err error
+}{
+	{regexp.MustCompile(`^Jogador (\d) excedeu o tempo limite`), referee.ErrTimeout},
+	{regexp.MustCompile(`^Jogador (\d) falhou com um erro`), referee.ErrPlayerFailed},
+	{regexp.MustCompile(`^Jogador (\d) poderia ter jogado`), referee.ErrIllegalPass},
+	{regexp.MustCompile(`^Jogador (\d) jogou uma pedra com um lado inválido`), referee.ErrInvalidEdge},
+	{regexp.MustCompile(`^Jogador (\d) jogou uma pedra que não tinha na mão`), referee.ErrNotInHand},
+	{regexp.MustCompile(`^Jogador (\d) jogou uma pedra que não encaixa`), referee.ErrNotGlueable},
+}
+
+// Parse reads every game of a log, source names it in the errors
+func Parse(r io.Reader, source string) ([]*Game, error) {
+	p := parser{source: source}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		p.line++
+		if err := p.parse(scanner.Text()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if p.game != nil {
+		return nil, p.errorf("game without a result")
+	}
+
+	return p.games, nil
+}
+
+func ParseFile(name string) ([]*Game, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f, name)
+}
+
+// ParseDir parses the .txt logs of dir in name order
+func ParseDir(dir string) ([]*Game, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	games := make([]*Game, 0, len(names))
+	for _, name := range names {
+		parsed, err := ParseFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		games = append(games, parsed...)
+	}
+
+	return games, nil
+}
+
+type parser struct {
+	source string
+	line   int
+	games  []*Game
+	game   *Game
+	bots   [models.DominoMaxPlayer]string
+	// dealt counts the hands read after "Pedras distribuídas:"
+	dealt int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s:%d: %s", ErrMalformed, p.source, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parse(line string) error {
+	if m := containerRe.FindStringSubmatch(line); m != nil {
+		player, _ := strconv.Atoi(m[1])
+		p.bots[player-models.DominoMinPlayer] = path.Base(m[2])
+		return nil
+	}
+
+	if line == "Pedras distribuídas:" {
+		if p.game != nil {
+			return p.errorf("deal before the end of the previous game")
+		}
+
+		p.game = &Game{Source: p.source, Bots: p.bots}
+		p.dealt = 0
+
+		return nil
+	}
+
+	if p.game == nil {
+		return nil
+	}
+
+	if p.dealt < models.DominoMaxPlayer {
+		m := dealRe.FindStringSubmatch(line)
+		if m == nil {
+			return p.errorf("expected the hand of player %d", p.dealt+models.DominoMinPlayer)
+		}
+
+		for _, bone := range boneRe.FindAllStringSubmatch(m[2], -1) {
+			domino, err := models.DominoFromString(bone[1])
+			if err != nil {
+				return p.errorf("%s", err)
+			}
+
+			p.game.Deal[p.dealt] = append(p.game.Deal[p.dealt], *domino)
+		}
+
+		p.dealt++
+
+		return nil
+	}
+
+	switch {
+	case openingRe.MatchString(line):
+		m := openingRe.FindStringSubmatch(line)
+		return p.turn(m[1], m[2], "")
+	case playRe.MatchString(line):
+		m := playRe.FindStringSubmatch(line)
+		return p.turn(m[1], m[2], m[3])
+	case passRe.MatchString(line):
+		m := passRe.FindStringSubmatch(line)
+		return p.turn(m[1], "", "")
+	case dominoRe.MatchString(line):
+		m := dominoRe.FindStringSubmatch(line)
+		p.game.Outcome = referee.OutcomeDomino
+		p.game.Player = player(m[1])
+		p.game.Winner = referee.TeamOf(p.game.Player)
+	case closedRe.MatchString(line):
+		p.game.Outcome = referee.OutcomeClosed
+		for i := len(p.game.Turns) - 1; i >= 0; i-- {
+			if !p.game.Turns[i].Pass() {
+				p.game.Player = p.game.Turns[i].PlayerPosition
+				break
+			}
+		}
+	case p.game.Outcome == referee.OutcomeClosed && pointsRe.MatchString(line):
+		m := pointsRe.FindStringSubmatch(line)
+		p.game.Points[player(m[1])-models.DominoMinPlayer], _ = strconv.Atoi(m[2])
+	case teamWinRe.MatchString(line):
+		p.game.Winner = referee.TeamOf(player(teamWinRe.FindStringSubmatch(line)[1]))
+	case tieRe.MatchString(line):
+		p.game.Winner = referee.TeamOf(player(tieRe.FindStringSubmatch(line)[1])).Other()
+	case winnerRe.MatchString(line):
+		if p.game.Outcome == 0 || p.game.Winner == 0 {
+			return p.errorf("winner without an outcome")
+		}
+
+		p.games = append(p.games, p.game)
+		p.game = nil
+	default:
+		for _, d := range disqualifications {
+			if m := d.re.FindStringSubmatch(line); m != nil {
+				p.game.Outcome = referee.OutcomeDisqualified
+				p.game.Player = player(m[1])
+				p.game.Winner = referee.TeamOf(p.game.Player).Other()
+				p.game.Err = d.err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) turn(playerText, bone, side string) error {
+	turn := models.DominoPlayWithPass{PlayerPosition: player(playerText)}
+
+	if bone != "" {
+		domino, err := models.DominoFromString(bone)
+		if err != nil {
+			return p.errorf("%s", err)
+		}
+
+		edge := models.LeftEdge
+		if side == "direita" {
+			edge = models.RightEdge
+		}
+
+		turn.Bone = &models.DominoInTable{Edge: edge, Domino: *domino}
+	}
+
+	p.game.Turns = append(p.game.Turns, turn)
+
+	return nil
+}
+
+func player(text string) models.PlayerPosition {
+	n, _ := strconv.Atoi(strings.TrimSpace(text))
+	return models.PlayerPosition(n)
+}
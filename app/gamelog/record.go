@@ -0,0 +1,44 @@
+package gamelog
+
+import (
+	"github.com/josecleiton/domino/app/models"
+)
+
+// Record is the game as a models.GameRecord, with the deal in the seats and
+// every turn, passes included, in the plays
+func (g *Game) Record() models.GameRecord {
+	record := models.GameRecord{
+		Version: models.GameRecordVersion,
+		Source:  g.Source,
+		Seats:   make([]models.SeatRecord, 0, len(g.Deal)),
+		Plays:   make([]models.PlayRecord, 0, len(g.Turns)),
+		Result: &models.ResultRecord{
+			Winner:  int(g.Winner),
+			Outcome: g.Outcome.String(),
+			Player:  g.Player,
+		},
+	}
+
+	for i, hand := range g.Deal {
+		bones := make([]string, 0, len(hand))
+		for _, bone := range hand {
+			bones = append(bones, models.DominoToString(bone))
+		}
+
+		record.Seats = append(record.Seats, models.SeatRecord{
+			Player: models.PlayerPosition(i + models.DominoMinPlayer),
+			Bot:    g.Bots[i],
+			Hand:   bones,
+		})
+	}
+
+	for _, turn := range g.Turns {
+		record.Plays = append(record.Plays, models.PlayRecordFromPlay(turn))
+	}
+
+	if g.Err != nil {
+		record.Result.Error = g.Err.Error()
+	}
+
+	return record
+}
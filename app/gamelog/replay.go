@@ -0,0 +1,109 @@
+package gamelog
+
+import (
+	"fmt"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+// script answers the referee with the turns of a log
+type script struct {
+	game *Game
+	// next turn, the opening is placed by the referee
+	next int
+}
+
+func (s *script) Play(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	if s.next == len(s.game.Turns) {
+		if s.game.Err != nil {
+			return models.DominoPlayWithPass{}, s.game.Err
+		}
+
+		return models.DominoPlayWithPass{}, fmt.Errorf("%w: %s: no turn of player %d", ErrMalformed, s.game.Source, state.PlayerPosition)
+	}
+
+	turn := s.game.Turns[s.next]
+	s.next++
+
+	if turn.PlayerPosition != state.PlayerPosition {
+		return turn, fmt.Errorf(
+			"%w: %s: turn %d is of player %d, not %d",
+			ErrMalformed,
+			s.game.Source,
+			s.next-1,
+			turn.PlayerPosition,
+			state.PlayerPosition,
+		)
+	}
+
+	return turn, nil
+}
+
+// Result plays the game again with the referee, which orients the bones
+// on the table and checks that the log is consistent
+func (g *Game) Result() (referee.Result, error) {
+	s := &script{game: g, next: 1}
+
+	match := referee.Match{}
+	for i := range match.Players {
+		match.Players[i] = s
+	}
+
+	result := match.Run(g.Deal)
+
+	if s.next != len(g.Turns) {
+		return result, fmt.Errorf("%w: %s: %d of %d turns replayed", ErrMalformed, g.Source, s.next, len(g.Turns))
+	}
+
+	if result.Outcome != g.Outcome || result.Winner != g.Winner || result.Player != g.Player {
+		return result, fmt.Errorf(
+			"%w: %s: replay ended with %v by player %d, log with %v by player %d",
+			ErrMalformed,
+			g.Source,
+			result.Outcome,
+			result.Player,
+			g.Outcome,
+			g.Player,
+		)
+	}
+
+	return result, nil
+}
+
+// Decision is a turn of the log with the state the player was sent
+type Decision struct {
+	Turn  int
+	State *models.DominoGameState
+	Play  models.DominoPlayWithPass
+}
+
+// Decisions replays the game keeping what every player was asked, the
+// opening isn't one
+func (g *Game) Decisions() ([]Decision, error) {
+	s := &script{game: g, next: 1}
+	decisions := make([]Decision, 0, len(g.Turns))
+
+	record := referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+		turn := s.next
+		play, err := s.Play(state)
+		if err == nil && turn < len(g.Turns) {
+			decisions = append(decisions, Decision{Turn: turn, State: state, Play: play})
+		}
+
+		return play, err
+	})
+
+	match := referee.Match{}
+	for i := range match.Players {
+		match.Players[i] = record
+	}
+
+	match.Run(g.Deal)
+
+	if s.next != len(g.Turns) {
+		return decisions, fmt.Errorf("%w: %s: %d of %d turns replayed", ErrMalformed, g.Source, s.next, len(g.Turns))
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,341 @@
+package inference
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+const (
+	DefaultParticles = 1024
+	sampleAttempts   = 64
+)
+
+// Beliefs is where a seat thinks the bones it can't see are. Passes are hard
+// constraints, the pips a seat lacks. The plays are soft ones: the deals are
+// weighted as if every player picked uniformly among its legal bones, or as
+// its Policy says, so a seat that keeps avoiding a pip it could play
+// probably has few bones of it.
+type Beliefs struct {
+	Player      models.PlayerPosition
+	Hand        models.BoneSet
+	Unseen      models.BoneSet
+	HandSizes   [models.DominoMaxPlayer]int
+	Unavailable [models.DominoMaxPlayer]models.PipSet
+	// Probabilities is indexed by models.Domino.Index and the seat minus
+	// models.DominoMinPlayer
+	Probabilities    [models.DominoMaxLength][models.DominoMaxPlayer]float64
+	Particles        []Particle
+	EffectiveSamples float64
+	// Policies replace the uniform choice of the seats that have one
+	Policies [models.DominoMaxPlayer]Policy
+
+	cumulative []float64
+}
+
+// Particle is a deal of the hidden bones and how likely it's given the plays
+type Particle struct {
+	Hands  [models.DominoMaxPlayer]models.BoneSet
+	Weight float64
+}
+
+// Policy is how a seat picks among its legal bones
+type Policy interface {
+	// Likelihood that the player of turns[i] played its bone holding hand
+	Likelihood(turns []Turn, i int, hand models.BoneSet) float64
+}
+
+// Infer builds the beliefs of the player to move, seeded by the state so
+// the same request has the same beliefs. unavailable may add pips known
+// to be missing from elsewhere, it can be nil.
+func Infer(
+	state *models.DominoGameState,
+	unavailable models.UnavailableBonesPlayer,
+) *Beliefs {
+	return InferWith(state, unavailable, [models.DominoMaxPlayer]Policy{})
+}
+
+// InferWith is Infer with the policies of the seats known to the player
+func InferWith(
+	state *models.DominoGameState,
+	unavailable models.UnavailableBonesPlayer,
+	policies [models.DominoMaxPlayer]Policy,
+) *Beliefs {
+	h := fnv.New64a()
+	for _, bone := range state.Hand {
+		h.Write([]byte{byte(bone.Index())})
+	}
+	h.Write([]byte{byte(state.PlayerPosition), byte(len(state.Plays))})
+
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	return NewWithPolicies(state, unavailable, policies, DefaultParticles, rng)
+}
+
+func New(
+	state *models.DominoGameState,
+	unavailable models.UnavailableBonesPlayer,
+	particles int,
+	rng *rand.Rand,
+) *Beliefs {
+	return NewWithPolicies(state, unavailable, [models.DominoMaxPlayer]Policy{}, particles, rng)
+}
+
+func NewWithPolicies(
+	state *models.DominoGameState,
+	unavailable models.UnavailableBonesPlayer,
+	policies [models.DominoMaxPlayer]Policy,
+	particles int,
+	rng *rand.Rand,
+) *Beliefs {
+	b := &Beliefs{
+		Player:   state.PlayerPosition,
+		Hand:     models.BoneSetOf(state.Hand...),
+		Policies: policies,
+	}
+
+	turns := History(state)
+	b.Unavailable = Unavailable(turns)
+	for i, pips := range unavailable.PipSets() {
+		b.Unavailable[i] |= pips
+	}
+
+	set := state.Set()
+	for i := range b.HandSizes {
+		b.HandSizes[i] = set.HandLength()
+	}
+
+	seen := b.Hand
+	for _, play := range state.Plays {
+		seen = seen.Add(play.Bone.Domino)
+		b.HandSizes[play.PlayerPosition-models.DominoMinPlayer]--
+	}
+
+	me := b.Player - models.DominoMinPlayer
+	b.HandSizes[me] = len(state.Hand)
+	b.Unseen = set.Bones().Minus(seen)
+	b.Unavailable[me] = 0
+
+	b.Particles = make([]Particle, 0, particles)
+	for i := 0; i < particles; i++ {
+		hands := b.deal(rng)
+		b.Particles = append(b.Particles, Particle{
+			Hands:  hands,
+			Weight: b.likelihood(turns, hands),
+		})
+	}
+
+	b.estimate()
+
+	return b
+}
+
+// Probability that player holds bone
+func (b *Beliefs) Probability(bone models.Domino, player models.PlayerPosition) float64 {
+	return b.Probabilities[bone.Index()][player-models.DominoMinPlayer]
+}
+
+// PipProbability that player holds at least one bone with pip
+func (b *Beliefs) PipProbability(player models.PlayerPosition, pip int) float64 {
+	idx := player - models.DominoMinPlayer
+	with, total := 0.0, 0.0
+	for _, particle := range b.Particles {
+		total += particle.Weight
+		if !particle.Hands[idx].Intersect(models.BonesWithPip(pip)).Empty() {
+			with += particle.Weight
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return with / total
+}
+
+// Sample draws a deal of the hidden bones from the posterior
+func (b *Beliefs) Sample(rng *rand.Rand) [models.DominoMaxPlayer]models.BoneSet {
+	total := b.cumulative[len(b.cumulative)-1]
+	pick := rng.Float64() * total
+
+	i := sort.SearchFloat64s(b.cumulative, pick)
+	if i == len(b.Particles) {
+		i--
+	}
+
+	return b.Particles[i].Hands
+}
+
+func (b *Beliefs) estimate() {
+	b.cumulative = make([]float64, len(b.Particles))
+
+	total, squares := 0.0, 0.0
+	for i, particle := range b.Particles {
+		total += particle.Weight
+		squares += particle.Weight * particle.Weight
+		b.cumulative[i] = total
+
+		for seat, hand := range particle.Hands {
+			for rest := hand; !rest.Empty(); {
+				var bone models.Domino
+				bone, rest = rest.Pop()
+				b.Probabilities[bone.Index()][seat] += particle.Weight
+			}
+		}
+	}
+
+	if total == 0 {
+		return
+	}
+
+	b.EffectiveSamples = total * total / squares
+
+	for i := range b.Probabilities {
+		for seat := range b.Probabilities[i] {
+			b.Probabilities[i][seat] /= total
+		}
+	}
+}
+
+// likelihood of the plays of the other seats given their hands, each seat
+// held its hand plus whatever it played from that turn on
+func (b *Beliefs) likelihood(turns []Turn, hands [models.DominoMaxPlayer]models.BoneSet) float64 {
+	weight := 1.0
+	held := hands
+
+	for i := len(turns) - 1; i >= 0; i-- {
+		turn := turns[i]
+		if turn.Pass() || turn.Player == b.Player {
+			continue
+		}
+
+		idx := turn.Player - models.DominoMinPlayer
+		held[idx] = held[idx].Add(*turn.Bone)
+
+		if turn.Empty {
+			continue
+		}
+
+		if policy := b.Policies[idx]; policy != nil {
+			weight *= policy.Likelihood(turns, i, held[idx])
+			continue
+		}
+
+		options := held[idx].Intersect(models.BonesWithPip(turn.Left).Union(models.BonesWithPip(turn.Right)))
+		if !options.Empty() {
+			weight /= float64(options.Len())
+		}
+	}
+
+	return weight
+}
+
+// deal hands out the unseen bones, weighted by the room left in each hand
+// and among the sleeping ones, dropping the pass constraints when they can't
+// be met
+func (b *Beliefs) deal(rng *rand.Rand) [models.DominoMaxPlayer]models.BoneSet {
+	for i := 0; i < sampleAttempts; i++ {
+		if hands, ok := b.tryDeal(rng, true); ok {
+			return hands
+		}
+	}
+
+	hands, _ := b.tryDeal(rng, false)
+
+	return hands
+}
+
+func (b *Beliefs) tryDeal(
+	rng *rand.Rand,
+	constrained bool,
+) ([models.DominoMaxPlayer]models.BoneSet, bool) {
+	var hands [models.DominoMaxPlayer]models.BoneSet
+	room := b.HandSizes
+
+	me := b.Player - models.DominoMinPlayer
+	hands[me] = b.Hand
+	room[me] = 0
+
+	var bones [models.DominoMaxLength]models.Domino
+	n := 0
+	for rest := b.Unseen; !rest.Empty(); n++ {
+		bones[n], rest = rest.Pop()
+	}
+
+	// the bones no seat was dealt, when the set is larger than the deal
+	asleep := n
+	for _, size := range room {
+		asleep -= size
+	}
+	asleep = max(0, asleep)
+
+	for i := n - 1; i > 0; i-- {
+		j := rng.Intn(i + 1)
+		bones[i], bones[j] = bones[j], bones[i]
+	}
+
+	allowed := func(seat int, bone models.Domino) bool {
+		return room[seat] > 0 &&
+			(!constrained || !b.Unavailable[seat].Has(bone.L) && !b.Unavailable[seat].Has(bone.R))
+	}
+
+	seats := func(bone models.Domino) int {
+		count := 0
+		if asleep > 0 {
+			count++
+		}
+
+		for seat := range room {
+			if allowed(seat, bone) {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	// most constrained bones first
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && seats(bones[j]) < seats(bones[j-1]); j-- {
+			bones[j], bones[j-1] = bones[j-1], bones[j]
+		}
+	}
+
+	for _, bone := range bones[:n] {
+		total := asleep
+		for seat := range room {
+			if allowed(seat, bone) {
+				total += room[seat]
+			}
+		}
+
+		if total == 0 {
+			return hands, false
+		}
+
+		pick := rng.Intn(total)
+		if pick < asleep {
+			asleep--
+			continue
+		}
+		pick -= asleep
+
+		for seat := range room {
+			if !allowed(seat, bone) {
+				continue
+			}
+
+			if pick -= room[seat]; pick >= 0 {
+				continue
+			}
+
+			hands[seat] = hands[seat].Add(bone)
+			room[seat]--
+			break
+		}
+	}
+
+	return hands, true
+}
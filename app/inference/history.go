@@ -0,0 +1,101 @@
+package inference
+
+import "github.com/josecleiton/domino/app/models"
+
+// Turn is a play or a pass recovered from the plays of a match, with the
+// open pips the player faced
+type Turn struct {
+	Player      models.PlayerPosition
+	Bone        *models.Domino
+	Left, Right int
+	// Empty is the opening of the match, there were no open pips
+	Empty bool
+}
+
+func (t Turn) Pass() bool {
+	return t.Bone == nil
+}
+
+// History replays the plays, the passes are the seats skipped between two
+// plays and before the player to move
+func History(state *models.DominoGameState) []Turn {
+	turns := make([]Turn, 0, len(state.Plays)+models.DominoMaxPlayer)
+
+	left, right, empty := 0, 0, true
+	passes := func(from, to models.PlayerPosition) {
+		for player := from; player != to; player = player.Next() {
+			turns = append(turns, Turn{Player: player, Left: left, Right: right})
+		}
+	}
+
+	for i, play := range state.Plays {
+		if i > 0 {
+			passes(state.Plays[i-1].PlayerPosition.Next(), play.PlayerPosition)
+		}
+
+		bone := play.Bone.Domino
+		turns = append(turns, Turn{
+			Player: play.PlayerPosition,
+			Bone:   &bone,
+			Left:   left,
+			Right:  right,
+			Empty:  empty,
+		})
+
+		switch {
+		case empty:
+			left, right, empty = bone.L, bone.R, false
+		case play.Bone.Edge == models.LeftEdge:
+			left = otherSide(bone, left)
+		default:
+			right = otherSide(bone, right)
+		}
+	}
+
+	if len(state.Plays) > 0 {
+		passes(state.Plays[len(state.Plays)-1].PlayerPosition.Next(), state.PlayerPosition)
+	}
+
+	return turns
+}
+
+// Unavailable is the pips each seat lacks given its passes
+func Unavailable(turns []Turn) [models.DominoMaxPlayer]models.PipSet {
+	var pips [models.DominoMaxPlayer]models.PipSet
+	for _, turn := range turns {
+		if !turn.Pass() {
+			continue
+		}
+
+		idx := turn.Player - models.DominoMinPlayer
+		pips[idx] = pips[idx].Add(turn.Left).Add(turn.Right)
+	}
+
+	return pips
+}
+
+// UnavailableBones is every pip each seat lacks, derived from the plays
+// alone, a request tells as much as the whole match before it
+func UnavailableBones(state *models.DominoGameState) models.UnavailableBonesPlayer {
+	table := make(models.UnavailableBonesPlayer, models.DominoMaxPlayer)
+	for i, pips := range Unavailable(History(state)) {
+		bones := make(models.TableBone, models.DominoUniqueBones)
+		for pip := 0; pip < models.DominoMaxPips; pip++ {
+			if pips.Has(pip) {
+				bones[pip] = true
+			}
+		}
+
+		table[models.PlayerPosition(i+models.DominoMinPlayer)] = bones
+	}
+
+	return table
+}
+
+func otherSide(bone models.Domino, side int) int {
+	if bone.L == side {
+		return bone.R
+	}
+
+	return bone.L
+}
@@ -0,0 +1,248 @@
+package models
+
+import "math/bits"
+
+// BoneSet has one bit per bone of the largest set, the bones of every set
+// first: double-six in the order of AllDominoes, then the ones double-nine
+// adds, then the ones of double-twelve
+type BoneSet struct {
+	lo, hi uint64
+}
+
+// PipSet has one bit per pip, from DominoMinBone to the highest pip of the set
+type PipSet uint16
+
+var (
+	// AllBones and AllPips are of the double-six set
+	AllBones = DoubleSix.Bones()
+	AllPips  = DoubleSix.Pips()
+)
+
+var (
+	boneIndex [DominoMaxPips][DominoMaxPips]int
+	boneAt    [DominoMaxLength]Domino
+	pipBones  [DominoMaxPips]BoneSet
+)
+
+func init() {
+	var placed [DominoMaxPips][DominoMaxPips]bool
+
+	i := 0
+	for _, set := range []DominoSet{DoubleSix, DoubleNine, DoubleTwelve} {
+		for _, bone := range set.Dominoes() {
+			if placed[bone.L][bone.R] {
+				continue
+			}
+			placed[bone.L][bone.R] = true
+
+			boneIndex[bone.L][bone.R] = i
+			boneIndex[bone.R][bone.L] = i
+			boneAt[i] = bone
+			i++
+
+			pipBones[bone.L] = pipBones[bone.L].Add(bone)
+			pipBones[bone.R] = pipBones[bone.R].Add(bone)
+		}
+	}
+}
+
+// bonesUpTo is the set of the first n bones
+func bonesUpTo(n int) BoneSet {
+	if n <= 64 {
+		return BoneSet{lo: 1<<n - 1}
+	}
+
+	return BoneSet{lo: ^uint64(0), hi: 1<<(n-64) - 1}
+}
+
+// BoneSetOf works with both orientations of the bones
+func BoneSetOf(dominoes ...Domino) BoneSet {
+	var set BoneSet
+	for _, bone := range dominoes {
+		set = set.Add(bone)
+	}
+
+	return set
+}
+
+// BonesWithPip is every bone that has pip on one of its sides
+func BonesWithPip(pip int) BoneSet {
+	return pipBones[pip]
+}
+
+// Index is the position of the bone in BoneSet, in any orientation, the
+// same as in AllDominoes for double-six
+func (d Domino) Index() int {
+	return boneIndex[d.L][d.R]
+}
+
+func BoneAt(index int) Domino {
+	return boneAt[index]
+}
+
+func (d Domino) Bit() BoneSet {
+	i := boneIndex[d.L][d.R]
+	if i < 64 {
+		return BoneSet{lo: 1 << i}
+	}
+
+	return BoneSet{hi: 1 << (i - 64)}
+}
+
+func (s BoneSet) Has(bone Domino) bool {
+	return !s.Intersect(bone.Bit()).Empty()
+}
+
+func (s BoneSet) Add(bone Domino) BoneSet {
+	return s.Union(bone.Bit())
+}
+
+func (s BoneSet) Remove(bone Domino) BoneSet {
+	return s.Minus(bone.Bit())
+}
+
+func (s BoneSet) Union(other BoneSet) BoneSet {
+	return BoneSet{lo: s.lo | other.lo, hi: s.hi | other.hi}
+}
+
+func (s BoneSet) Intersect(other BoneSet) BoneSet {
+	return BoneSet{lo: s.lo & other.lo, hi: s.hi & other.hi}
+}
+
+func (s BoneSet) Minus(other BoneSet) BoneSet {
+	return BoneSet{lo: s.lo &^ other.lo, hi: s.hi &^ other.hi}
+}
+
+func (s BoneSet) Len() int {
+	return bits.OnesCount64(s.lo) + bits.OnesCount64(s.hi)
+}
+
+func (s BoneSet) Empty() bool {
+	return s.lo == 0 && s.hi == 0
+}
+
+// Pop splits the set in its lowest bone, with L <= R, and the rest
+func (s BoneSet) Pop() (Domino, BoneSet) {
+	if s.lo != 0 {
+		return boneAt[bits.TrailingZeros64(s.lo)], BoneSet{lo: s.lo & (s.lo - 1), hi: s.hi}
+	}
+
+	return boneAt[64+bits.TrailingZeros64(s.hi)], BoneSet{hi: s.hi & (s.hi - 1)}
+}
+
+func (s BoneSet) Sum() int {
+	sum := 0
+	for rest := s; !rest.Empty(); {
+		var bone Domino
+		bone, rest = rest.Pop()
+		sum += bone.Sum()
+	}
+
+	return sum
+}
+
+// Avoiding removes the bones with any of the pips
+func (s BoneSet) Avoiding(pips PipSet) BoneSet {
+	for rest := pips; rest != 0; rest &= rest - 1 {
+		s = s.Minus(pipBones[bits.TrailingZeros16(uint16(rest))])
+	}
+
+	return s
+}
+
+// Matching keeps the bones with any of the pips
+func (s BoneSet) Matching(pips PipSet) BoneSet {
+	var matching BoneSet
+	for rest := pips; rest != 0; rest &= rest - 1 {
+		matching = matching.Union(pipBones[bits.TrailingZeros16(uint16(rest))])
+	}
+
+	return s.Intersect(matching)
+}
+
+func (s BoneSet) Pips() PipSet {
+	var pips PipSet
+	for rest := s; !rest.Empty(); {
+		var bone Domino
+		bone, rest = rest.Pop()
+		pips = pips.Add(bone.L).Add(bone.R)
+	}
+
+	return pips
+}
+
+// Dominoes allocates, prefer Pop inside searches
+func (s BoneSet) Dominoes() []Domino {
+	dominoes := make([]Domino, 0, s.Len())
+	for rest := s; !rest.Empty(); {
+		var bone Domino
+		bone, rest = rest.Pop()
+		dominoes = append(dominoes, bone)
+	}
+
+	return dominoes
+}
+
+func PipSetOf(pips ...int) PipSet {
+	var set PipSet
+	for _, pip := range pips {
+		set = set.Add(pip)
+	}
+
+	return set
+}
+
+func (s PipSet) Has(pip int) bool {
+	return s&(1<<pip) != 0
+}
+
+func (s PipSet) Add(pip int) PipSet {
+	return s | 1<<pip
+}
+
+func (s PipSet) Remove(pip int) PipSet {
+	return s &^ (1 << pip)
+}
+
+func (s PipSet) Len() int {
+	return bits.OnesCount16(uint16(s))
+}
+
+// BoneSet is the bones on the table
+func (table TableMap) BoneSet() BoneSet {
+	var set BoneSet
+	for l, bones := range table {
+		for r, ok := range bones {
+			if ok {
+				set = set.Add(Domino{L: l, R: r})
+			}
+		}
+	}
+
+	return set
+}
+
+func (table TableBone) PipSet() PipSet {
+	var set PipSet
+	for pip, ok := range table {
+		if ok {
+			set = set.Add(pip)
+		}
+	}
+
+	return set
+}
+
+// PipSets is indexed by the player position minus DominoMinPlayer
+func (table UnavailableBonesPlayer) PipSets() [DominoMaxPlayer]PipSet {
+	var sets [DominoMaxPlayer]PipSet
+	for player, bones := range table {
+		if player < DominoMinPlayer || player > DominoMaxPlayer {
+			continue
+		}
+
+		sets[player-DominoMinPlayer] = bones.PipSet()
+	}
+
+	return sets
+}
@@ -2,12 +2,21 @@ package models
 
 import "fmt"
 
+// DominoLength, DominoUniqueBones, DominoHandLength and DominoMaxBone are
+// of double-six, the default set, DominoSet has the ones of the others
 const DominoLength = 28
 const DominoUniqueBones = 7
 const DominoHandLength = 7
 const DominoMaxBone = 6
 const DominoMinBone = 0
 
+// of the largest set, double-twelve
+const (
+	DominoMaxLength     = 91
+	DominoMaxPips       = 13
+	DominoMaxHandLength = 15
+)
+
 type Domino struct {
 	L, R int
 }
@@ -42,15 +51,22 @@ func (d Domino) Equals(other Domino) bool {
 	return false
 }
 
+func DominoToString(d Domino) string {
+	return fmt.Sprintf("%d-%d", d.L, d.R)
+}
+
 func DominoFromString(s string) (*Domino, error) {
 	var a, b int
 
-	fmt.Sscanf(s, "%d-%d", &a, &b)
+	_, err := fmt.Sscanf(s, "%d-%d", &a, &b)
+	if err != nil || s != DominoToString(Domino{L: a, R: b}) {
+		return nil, fmt.Errorf("invalid bone: %q", s)
+	}
 
-	if a < DominoMinBone || a > DominoMaxBone {
+	if a < DominoMinBone || a > DoubleTwelve.MaxBone() {
 		return nil, fmt.Errorf("invalid bone: %d", a)
 	}
-	if b < DominoMinBone || b > DominoMaxBone {
+	if b < DominoMinBone || b > DoubleTwelve.MaxBone() {
 		return nil, fmt.Errorf("invalid bone: %d", b)
 	}
 
@@ -59,3 +75,8 @@ func DominoFromString(s string) (*Domino, error) {
 		R: b,
 	}, nil
 }
+
+// AllDominoes of double-six
+func AllDominoes() []Domino {
+	return DoubleSix.Dominoes()
+}
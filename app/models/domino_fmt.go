@@ -11,7 +11,13 @@ const (
 	hairSpace               = ' '
 )
 
+// String is the Unicode tile of the double-six bones, there's none for the
+// pips of the larger sets
 func (d Domino) String() string {
+	if !DoubleSix.Has(d) {
+		return fmt.Sprintf("[%d|%d]", d.L, d.R)
+	}
+
 	offset := d.L*DominoUniqueBones + d.R
 
 	space := hairSpace
@@ -0,0 +1,104 @@
+package models
+
+import "fmt"
+
+// DominoSet is a set of dominoes by its highest pip, zero is double-six
+type DominoSet int
+
+const (
+	DoubleSix    DominoSet = 6
+	DoubleNine   DominoSet = 9
+	DoubleTwelve DominoSet = 12
+)
+
+// DominoSets by name, for flags and headers
+var DominoSets = map[string]DominoSet{
+	"double-six":    DoubleSix,
+	"double-nine":   DoubleNine,
+	"double-twelve": DoubleTwelve,
+}
+
+// ParseDominoSet reads the name of a set or its highest pip
+func ParseDominoSet(s string) (DominoSet, error) {
+	if set, ok := DominoSets[s]; ok {
+		return set, nil
+	}
+
+	var pip int
+	if _, err := fmt.Sscanf(s, "%d", &pip); err == nil {
+		switch set := DominoSet(pip); set {
+		case DoubleSix, DoubleNine, DoubleTwelve:
+			return set, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown domino set %q", s)
+}
+
+func (s DominoSet) String() string {
+	for name, set := range DominoSets {
+		if set == s.normal() {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("DominoSet(%d)", int(s))
+}
+
+func (s DominoSet) normal() DominoSet {
+	if s == 0 {
+		return DoubleSix
+	}
+
+	return s
+}
+
+func (s DominoSet) MaxBone() int {
+	return int(s.normal())
+}
+
+// UniqueBones is how many pips the set has, and how many bones of each pip
+func (s DominoSet) UniqueBones() int {
+	return s.MaxBone() + 1
+}
+
+func (s DominoSet) Length() int {
+	return s.UniqueBones() * (s.UniqueBones() + 1) / 2
+}
+
+// HandLength dealt to each of the 4 seats, the bones left sleep
+func (s DominoSet) HandLength() int {
+	switch s.normal() {
+	case DoubleNine:
+		return 10
+	case DoubleTwelve:
+		return DominoMaxHandLength
+	}
+
+	return DominoHandLength
+}
+
+// Dominoes of the set, with L <= R
+func (s DominoSet) Dominoes() []Domino {
+	dominoes := make([]Domino, 0, s.Length())
+	for i := DominoMinBone; i <= s.MaxBone(); i++ {
+		for j := i; j <= s.MaxBone(); j++ {
+			dominoes = append(dominoes, Domino{L: i, R: j})
+		}
+	}
+
+	return dominoes
+}
+
+// Bones of the set, the first ones of BoneSet
+func (s DominoSet) Bones() BoneSet {
+	return bonesUpTo(s.Length())
+}
+
+func (s DominoSet) Pips() PipSet {
+	return PipSet(1<<s.UniqueBones() - 1)
+}
+
+func (s DominoSet) Has(d Domino) bool {
+	return d.L >= DominoMinBone && d.L <= s.MaxBone() && d.R >= DominoMinBone && d.R <= s.MaxBone()
+}
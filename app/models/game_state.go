@@ -37,6 +37,33 @@ type DominoGameState struct {
 	Table          []Domino
 	TableMap       TableMap
 	Plays          []DominoPlay
+	// Goal of the match, nil under the README rules
+	Goal *Goal
+}
+
+// Set of the dominoes of the match, by the rules of the goal
+func (s DominoGameState) Set() DominoSet {
+	if s.Goal == nil {
+		return DoubleSix
+	}
+
+	return s.Goal.Rules.Set.normal()
+}
+
+// Rules of the match, the README ones without a goal
+func (s DominoGameState) Rules() Ruleset {
+	if s.Goal == nil {
+		return ClassicRules
+	}
+
+	return s.Goal.Rules
+}
+
+// opening is the bone the hand must open with, false when any will do. The
+// seat asked to open holds the opening, so the highest double of its own
+// hand is the highest one dealt.
+func (s DominoGameState) opening() (Domino, bool) {
+	return s.Rules().Opening(BoneSetOf(s.Hand...))
 }
 
 func (s DominoGameState) Edges() Edges {
@@ -46,6 +73,97 @@ func (s DominoGameState) Edges() Edges {
 	}
 }
 
+func (s DominoGameState) LegalPlays() []DominoPlayWithPass {
+	plays := make([]DominoPlayWithPass, 0, len(s.Hand)*DominoMaxEdges)
+
+	if len(s.Table) == 0 {
+		opening, ok := s.opening()
+		for i := range s.Hand {
+			if ok && !s.Hand[i].Equals(opening) {
+				continue
+			}
+
+			plays = append(plays, DominoPlayWithPass{
+				PlayerPosition: s.PlayerPosition,
+				Bone:           &DominoInTable{Edge: LeftEdge, Domino: s.Hand[i]},
+			})
+		}
+
+		return plays
+	}
+
+	edges := s.Edges()
+	for _, bone := range s.Hand {
+		for _, edge := range []Edge{LeftEdge, RightEdge} {
+			table := DominoInTable{Edge: edge, Domino: *edges[edge]}
+			if table.Glue(bone) == nil {
+				continue
+			}
+
+			plays = append(plays, DominoPlayWithPass{
+				PlayerPosition: s.PlayerPosition,
+				Bone:           &DominoInTable{Edge: edge, Domino: bone},
+			})
+		}
+	}
+
+	return plays
+}
+
+// CheckPlay tells why play isn't legal: a bone not in the hand, an opening
+// other than the one of the rules, a bone that doesn't glue to its edge or
+// a pass with a legal play
+func (s DominoGameState) CheckPlay(play DominoPlayWithPass) error {
+	if play.Pass() {
+		if plays := s.LegalPlays(); len(plays) > 0 {
+			return fmt.Errorf("pass with %d legal plays", len(plays))
+		}
+
+		return nil
+	}
+
+	if !BoneSetOf(s.Hand...).Has(play.Bone.Domino) {
+		return fmt.Errorf("bone %s is not in the hand", DominoToString(play.Bone.Domino))
+	}
+
+	if len(s.Table) == 0 {
+		if opening, ok := s.opening(); ok && !play.Bone.Domino.Equals(opening) {
+			return fmt.Errorf("the hand opens with %s, not %s", DominoToString(opening), DominoToString(play.Bone.Domino))
+		}
+
+		return nil
+	}
+
+	if play.Bone.Edge != LeftEdge && play.Bone.Edge != RightEdge {
+		return fmt.Errorf("edge %q is not of the table", play.Bone.Edge)
+	}
+
+	end := DominoInTable{Edge: play.Bone.Edge, Domino: *s.Edges()[play.Bone.Edge]}
+	if end.Glue(play.Bone.Domino) == nil {
+		return fmt.Errorf("bone %s does not glue to the %s edge", DominoToString(play.Bone.Domino), play.Bone.Edge)
+	}
+
+	return nil
+}
+
+func TableMapFromDominoes(dominoes []Domino) TableMap {
+	table := make(TableMap, DominoUniqueBones)
+	for _, domino := range dominoes {
+		if _, ok := table[domino.L]; !ok {
+			table[domino.L] = make(TableBone, DominoUniqueBones)
+		}
+
+		if _, ok := table[domino.R]; !ok {
+			table[domino.R] = make(TableBone, DominoUniqueBones)
+		}
+
+		table[domino.L][domino.R] = true
+		table[domino.R][domino.L] = true
+	}
+
+	return table
+}
+
 func (play DominoPlayWithPass) Pass() bool {
 	return play.Bone == nil
 }
@@ -0,0 +1,281 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GameRecordVersion is written in every record, records of newer versions
+// are rejected
+const GameRecordVersion = 1
+
+const (
+	SideLeft  = "esquerda"
+	SideRight = "direita"
+)
+
+var (
+	ErrRecordVersion = errors.New("unsupported game record version")
+	ErrRecordInvalid = errors.New("invalid game record")
+)
+
+// GameRecord is a match, or a moment of it, as JSON. jogador, mao, mesa and
+// jogadas are the fields of the requests of run_domino.js, so a request is
+// a record without a version. Passes are plays without pedra.
+type GameRecord struct {
+	Version int    `json:"version"`
+	Source  string `json:"source,omitempty"`
+	// Seats has the deal and who played each seat
+	Seats  []SeatRecord   `json:"seats,omitempty"`
+	Player PlayerPosition `json:"jogador,omitempty"`
+	Hand   []string       `json:"mao,omitempty"`
+	Table  []string       `json:"mesa,omitempty"`
+	Plays  []PlayRecord   `json:"jogadas"`
+	// Answer is the play chosen for the state
+	Answer *PlayRecord   `json:"answer,omitempty"`
+	Result *ResultRecord `json:"result,omitempty"`
+}
+
+type SeatRecord struct {
+	Player PlayerPosition `json:"player"`
+	Bot    string         `json:"bot,omitempty"`
+	Hand   []string       `json:"hand"`
+}
+
+type PlayRecord struct {
+	Player PlayerPosition `json:"jogador"`
+	Bone   string         `json:"pedra,omitempty"`
+	Side   string         `json:"lado,omitempty"`
+}
+
+type ResultRecord struct {
+	// Winner is 1 for players 1 and 3, 2 for players 2 and 4
+	Winner  int            `json:"winner"`
+	Outcome string         `json:"outcome"`
+	Player  PlayerPosition `json:"player,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func SideFromEdge(edge Edge) string {
+	if edge == RightEdge {
+		return SideRight
+	}
+
+	return SideLeft
+}
+
+// EdgeFromSide takes an empty side as the left edge, like the opening
+func EdgeFromSide(side string) (Edge, error) {
+	switch side {
+	case SideLeft, "":
+		return LeftEdge, nil
+	case SideRight:
+		return RightEdge, nil
+	}
+
+	return LeftEdge, fmt.Errorf("%w: side %q", ErrRecordInvalid, side)
+}
+
+func PlayRecordFromPlay(play DominoPlayWithPass) PlayRecord {
+	record := PlayRecord{Player: play.PlayerPosition}
+	if play.Bone != nil {
+		record.Bone = DominoToString(play.Bone.Domino)
+		record.Side = SideFromEdge(play.Bone.Edge)
+	}
+
+	return record
+}
+
+func (r PlayRecord) Play() (DominoPlayWithPass, error) {
+	play := DominoPlayWithPass{PlayerPosition: r.Player}
+	if r.Bone == "" {
+		return play, nil
+	}
+
+	domino, err := DominoFromString(r.Bone)
+	if err != nil {
+		return play, fmt.Errorf("%w: %s", ErrRecordInvalid, err)
+	}
+
+	edge, err := EdgeFromSide(r.Side)
+	if err != nil {
+		return play, err
+	}
+
+	play.Bone = &DominoInTable{Edge: edge, Domino: *domino}
+
+	return play, nil
+}
+
+// GameRecordFromState records what the player to move sees
+func GameRecordFromState(state *DominoGameState) GameRecord {
+	record := GameRecord{
+		Version: GameRecordVersion,
+		Player:  state.PlayerPosition,
+		Hand:    dominoStrings(state.Hand),
+		Table:   dominoStrings(state.Table),
+		Plays:   make([]PlayRecord, 0, len(state.Plays)),
+	}
+
+	for _, play := range state.Plays {
+		bone := play.Bone
+		record.Plays = append(record.Plays, PlayRecordFromPlay(DominoPlayWithPass{
+			PlayerPosition: play.PlayerPosition,
+			Bone:           &bone,
+		}))
+	}
+
+	return record
+}
+
+// State is what the player of the record sees. Without mao the hand comes
+// from the deal, without mesa the table is built from the plays.
+func (r GameRecord) State() (*DominoGameState, error) {
+	if r.Player < DominoMinPlayer || r.Player > DominoMaxPlayer {
+		return nil, fmt.Errorf("%w: player %d", ErrRecordInvalid, r.Player)
+	}
+
+	state := &DominoGameState{
+		PlayerPosition: r.Player,
+		Plays:          make([]DominoPlay, 0, len(r.Plays)),
+	}
+
+	played := make([]Domino, 0, len(r.Plays))
+	for _, record := range r.Plays {
+		play, err := record.Play()
+		if err != nil {
+			return nil, err
+		}
+
+		if play.Pass() {
+			continue
+		}
+
+		state.Plays = append(state.Plays, DominoPlay{PlayerPosition: play.PlayerPosition, Bone: *play.Bone})
+		if play.PlayerPosition == r.Player {
+			played = append(played, play.Bone.Domino)
+		}
+	}
+
+	hand, err := r.hand(played)
+	if err != nil {
+		return nil, err
+	}
+	state.Hand = hand
+
+	if r.Table != nil {
+		state.Table, err = dominoesFromStrings(r.Table)
+	} else {
+		replay := ReplayTable(state.Plays)
+		if len(replay.Discrepancies) > 0 {
+			err = fmt.Errorf("%w: %s", ErrRecordInvalid, replay.Discrepancies[0])
+		}
+		state.Table = replay.Chain
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	state.TableMap = TableMapFromDominoes(state.Table)
+
+	return state, nil
+}
+
+func (r GameRecord) hand(played []Domino) ([]Domino, error) {
+	if r.Hand != nil {
+		return dominoesFromStrings(r.Hand)
+	}
+
+	for _, seat := range r.Seats {
+		if seat.Player != r.Player {
+			continue
+		}
+
+		dealt, err := dominoesFromStrings(seat.Hand)
+		if err != nil {
+			return nil, err
+		}
+
+		hand := make([]Domino, 0, len(dealt))
+	next:
+		for _, bone := range dealt {
+			for _, p := range played {
+				if bone.Equals(p) {
+					continue next
+				}
+			}
+
+			hand = append(hand, bone)
+		}
+
+		return hand, nil
+	}
+
+	return nil, fmt.Errorf("%w: no hand of player %d", ErrRecordInvalid, r.Player)
+}
+
+// WriteGameRecord writes the record as a line of JSON Lines
+func WriteGameRecord(w io.Writer, record GameRecord) error {
+	if record.Version == 0 {
+		record.Version = GameRecordVersion
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(line, '\n'))
+
+	return err
+}
+
+// ReadGameRecords reads a JSON record or JSON Lines of them
+func ReadGameRecords(r io.Reader) ([]GameRecord, error) {
+	decoder := json.NewDecoder(r)
+	records := []GameRecord{}
+
+	for {
+		var record GameRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Version < 1 || record.Version > GameRecordVersion {
+			return nil, fmt.Errorf("%w: %d", ErrRecordVersion, record.Version)
+		}
+
+		records = append(records, record)
+	}
+}
+
+func dominoStrings(dominoes []Domino) []string {
+	strings := make([]string, 0, len(dominoes))
+	for _, bone := range dominoes {
+		strings = append(strings, DominoToString(bone))
+	}
+
+	return strings
+}
+
+func dominoesFromStrings(strings []string) ([]Domino, error) {
+	dominoes := make([]Domino, 0, len(strings))
+	for _, s := range strings {
+		domino, err := DominoFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrRecordInvalid, err)
+		}
+
+		dominoes = append(dominoes, *domino)
+	}
+
+	return dominoes, nil
+}
@@ -0,0 +1,298 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Ruleset of a match, the zero value is the README: a single hand of
+// double-six, won or lost, opened by 6-6
+type Ruleset struct {
+	// Set of the dominoes, double-six when zero
+	Set DominoSet
+	// Target points of a match, a single hand when zero
+	Target int
+	// Bonuses of the batida: a carroça is worth 2 plain ones, a lá-e-lô 3
+	// and a cruzada 4
+	Bonuses bool
+	// HighestDouble opens when the double of the highest pip isn't dealt,
+	// the highest bone when no double is. The sets larger than double-six
+	// always open so, some of their bones sleep.
+	HighestDouble bool
+	// Pips: a hand is worth the pips left in the hands of the losers instead
+	// of a point, closed hands included
+	Pips bool
+}
+
+var (
+	ClassicRules   = Ruleset{}
+	BrazilianRules = Ruleset{Target: 6, Bonuses: true, HighestDouble: true}
+	PointsRules    = Ruleset{Target: 100, Bonuses: true, HighestDouble: true, Pips: true}
+)
+
+// Rulesets by name, for flags and headers
+var Rulesets = map[string]Ruleset{
+	"classic":   ClassicRules,
+	"brazilian": BrazilianRules,
+	"points":    PointsRules,
+}
+
+const (
+	ruleSet           = "set"
+	ruleTarget        = "target"
+	ruleBonuses       = "bonuses"
+	ruleHighestDouble = "highest-double"
+	rulePips          = "pips"
+)
+
+// ParseRuleset reads the name of a ruleset or a comma separated list of
+// rules, like "set=double-nine,target=100,bonuses,highest-double,pips".
+// A name may be followed by rules, like "brazilian,set=double-twelve".
+func ParseRuleset(s string) (Ruleset, error) {
+	if rules, ok := Rulesets[s]; ok {
+		return rules, nil
+	}
+
+	var rules Ruleset
+	for i, rule := range strings.Split(s, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if preset, ok := Rulesets[name]; ok && i == 0 {
+			rules = preset
+			continue
+		}
+
+		switch name {
+		case ruleSet:
+			set, err := ParseDominoSet(value)
+			if err != nil {
+				return Ruleset{}, err
+			}
+
+			// zero is double-six, so the presets compare equal
+			if rules.Set = set; set == DoubleSix {
+				rules.Set = 0
+			}
+		case ruleTarget:
+			target, err := strconv.Atoi(value)
+			if err != nil || target < 0 {
+				return Ruleset{}, fmt.Errorf("invalid target %q", value)
+			}
+
+			rules.Target = target
+		case ruleBonuses:
+			rules.Bonuses = true
+		case ruleHighestDouble:
+			rules.HighestDouble = true
+		case rulePips:
+			rules.Pips = true
+		default:
+			return Ruleset{}, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	return rules, nil
+}
+
+// String is the name of the ruleset, otherwise what ParseRuleset reads
+func (r Ruleset) String() string {
+	set := r.Set.normal()
+	r.Set = 0
+
+	var rules []string
+	for _, name := range []string{"classic", "brazilian", "points"} {
+		if Rulesets[name] == r {
+			rules = append(rules, name)
+			break
+		}
+	}
+
+	if rules == nil {
+		rules = append(rules, fmt.Sprintf("%s=%d", ruleTarget, r.Target))
+		if r.Bonuses {
+			rules = append(rules, ruleBonuses)
+		}
+
+		if r.HighestDouble {
+			rules = append(rules, ruleHighestDouble)
+		}
+
+		if r.Pips {
+			rules = append(rules, rulePips)
+		}
+	}
+
+	if set != DoubleSix {
+		rules = append(rules, fmt.Sprintf("%s=%s", ruleSet, set))
+	}
+
+	return strings.Join(rules, ",")
+}
+
+// Points of a hand won by batida, BatidaNone when it was closed, with pips
+// left in the hands of the losers
+func (r Ruleset) Points(batida Batida, pips int) int {
+	points := 1
+	if r.Pips {
+		points = pips
+	}
+
+	if r.Bonuses && batida != BatidaNone {
+		points *= int(batida)
+	}
+
+	return points
+}
+
+// Over tells whether a team with points won the match
+func (r Ruleset) Over(points int) bool {
+	return points >= max(1, r.Target)
+}
+
+// Opening is the bone the hand starts with, false when the ruleset needs a
+// bone nobody holds
+func (r Ruleset) Opening(held BoneSet) (Domino, bool) {
+	opening := Domino{L: r.Set.MaxBone(), R: r.Set.MaxBone()}
+	if held.Has(opening) {
+		return opening, true
+	}
+
+	if !r.HighestDouble && r.Set.normal() == DoubleSix || held.Empty() {
+		return Domino{}, false
+	}
+
+	var highest Domino
+	found := false
+	for rest := held; !rest.Empty(); {
+		var bone Domino
+		bone, rest = rest.Pop()
+
+		switch {
+		case !found:
+		case bone.IsDouble() != highest.IsDouble():
+			if !bone.IsDouble() {
+				continue
+			}
+		case bone.Sum() <= highest.Sum():
+			continue
+		}
+
+		highest, found = bone, true
+	}
+
+	return highest, found
+}
+
+// Batida is how a hand was won, worth its value in plain ones with bonuses
+type Batida int
+
+const (
+	// BatidaNone is a closed hand
+	BatidaNone Batida = iota
+	BatidaPlain
+	// BatidaCarroca is a double
+	BatidaCarroca
+	// BatidaLaELo fits both edges
+	BatidaLaELo
+	// BatidaCruzada is a double that fits both edges
+	BatidaCruzada
+)
+
+// BatidaOf the last bone, played on a table with the open pips left and right
+func BatidaOf(bone Domino, left, right int) Batida {
+	fitsLeft := bone.L == left || bone.R == left
+	fitsRight := bone.L == right || bone.R == right
+
+	switch {
+	case bone.IsDouble() && fitsLeft && fitsRight:
+		return BatidaCruzada
+	case fitsLeft && fitsRight:
+		return BatidaLaELo
+	case bone.IsDouble():
+		return BatidaCarroca
+	}
+
+	return BatidaPlain
+}
+
+func (b Batida) String() string {
+	switch b {
+	case BatidaNone:
+		return "none"
+	case BatidaPlain:
+		return "plain"
+	case BatidaCarroca:
+		return "carroça"
+	case BatidaLaELo:
+		return "lá-e-lô"
+	case BatidaCruzada:
+		return "cruzada"
+	}
+
+	return fmt.Sprintf("Batida(%d)", int(b))
+}
+
+// Goal is the match a hand is part of: its rules and the points of the team
+// of the seat and of the other one
+type Goal struct {
+	Rules   Ruleset
+	Points  int
+	Against int
+}
+
+// Value of a hand outcome for the team of the seat, from 0 when it loses
+// the match to 1 when it wins it. A single hand is only won or lost.
+func (g Goal) Value(won bool, points int) float64 {
+	target := max(1, g.Rules.Target)
+
+	us, them := g.Points, g.Against
+	if won {
+		us += points
+	} else {
+		them += points
+	}
+
+	switch {
+	case us >= target:
+		return 1
+	case them >= target:
+		return 0
+	}
+
+	return 0.5 + 0.5*float64(us-them)/float64(target)
+}
+
+// ScoreString is how a goal travels in a header, the points of the team of
+// the seat first
+func (g Goal) ScoreString() string {
+	return fmt.Sprintf("%d-%d", g.Points, g.Against)
+}
+
+// ParseGoal reads the ruleset and the score of ScoreString
+func ParseGoal(rules, score string) (*Goal, error) {
+	ruleset, err := ParseRuleset(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	goal := &Goal{Rules: ruleset}
+	if score == "" {
+		return goal, nil
+	}
+
+	points, against, ok := strings.Cut(score, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid score %q", score)
+	}
+
+	if goal.Points, err = strconv.Atoi(points); err != nil || goal.Points < 0 {
+		return nil, fmt.Errorf("invalid score %q", score)
+	}
+
+	if goal.Against, err = strconv.Atoi(against); err != nil || goal.Against < 0 {
+		return nil, fmt.Errorf("invalid score %q", score)
+	}
+
+	return goal, nil
+}
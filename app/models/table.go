@@ -0,0 +1,182 @@
+package models
+
+import "fmt"
+
+type DiscrepancyKind string
+
+const (
+	// a play that doesn't glue to the end it was played on
+	DiscrepancyUnglued DiscrepancyKind = "unglued"
+	// a bone played more than once
+	DiscrepancyRepeated DiscrepancyKind = "repeated"
+	// the table has a bone other than the one the plays put there
+	DiscrepancyBone DiscrepancyKind = "bone"
+	// the table has the bone the plays put there, turned
+	DiscrepancyFlipped DiscrepancyKind = "flipped"
+	// the table has more or less bones than the plays
+	DiscrepancyLength DiscrepancyKind = "length"
+	// a bone played that isn't on the table
+	DiscrepancyMissing DiscrepancyKind = "missing"
+	// a bone on the table nobody played
+	DiscrepancyExtra DiscrepancyKind = "extra"
+)
+
+// Discrepancy between the plays and the table. Play is the index of the
+// play and Position the index in the table, -1 when they don't apply.
+// Want is what the plays put there, Got what the table has.
+type Discrepancy struct {
+	Kind     DiscrepancyKind
+	Play     int
+	Position int
+	Want     *Domino
+	Got      *Domino
+}
+
+func (d Discrepancy) String() string {
+	bone := func(bone *Domino) string {
+		if bone == nil {
+			return "none"
+		}
+
+		return DominoToString(*bone)
+	}
+
+	switch d.Kind {
+	case DiscrepancyUnglued:
+		return fmt.Sprintf("play %d: %s does not glue", d.Play, bone(d.Got))
+	case DiscrepancyRepeated:
+		return fmt.Sprintf("play %d: %s was already played", d.Play, bone(d.Got))
+	case DiscrepancyBone, DiscrepancyFlipped:
+		return fmt.Sprintf("table %d: %s, the plays put %s", d.Position, bone(d.Got), bone(d.Want))
+	case DiscrepancyLength:
+		return "table and plays differ in length"
+	case DiscrepancyMissing:
+		return fmt.Sprintf("%s was played and is not on the table", bone(d.Want))
+	case DiscrepancyExtra:
+		return fmt.Sprintf("%s is on the table and was not played", bone(d.Got))
+	}
+
+	return string(d.Kind)
+}
+
+// TableReplay is the table the plays build, from the left end to the right
+// one, with the plays it had to skip
+type TableReplay struct {
+	Chain         []Domino
+	Discrepancies []Discrepancy
+}
+
+// ReplayTable puts every play on the end of its edge, the first one as it
+// came
+func ReplayTable(plays []DominoPlay) TableReplay {
+	replay := TableReplay{Chain: make([]Domino, 0, len(plays))}
+
+	var played BoneSet
+	for i, play := range plays {
+		bone := play.Bone.Domino
+		if played.Has(bone) {
+			replay.Discrepancies = append(replay.Discrepancies, Discrepancy{
+				Kind:     DiscrepancyRepeated,
+				Play:     i,
+				Position: -1,
+				Got:      &bone,
+			})
+			continue
+		}
+
+		if len(replay.Chain) == 0 {
+			played = played.Add(bone)
+			replay.Chain = append(replay.Chain, bone)
+			continue
+		}
+
+		end := DominoInTable{Edge: play.Bone.Edge, Domino: replay.Chain[0]}
+		if play.Bone.Edge == RightEdge {
+			end.Domino = replay.Chain[len(replay.Chain)-1]
+		}
+
+		glued := end.Glue(bone)
+		if glued == nil {
+			replay.Discrepancies = append(replay.Discrepancies, Discrepancy{
+				Kind:     DiscrepancyUnglued,
+				Play:     i,
+				Position: -1,
+				Got:      &bone,
+			})
+			continue
+		}
+
+		played = played.Add(bone)
+		if play.Bone.Edge == LeftEdge {
+			replay.Chain = append([]Domino{*glued}, replay.Chain...)
+		} else {
+			replay.Chain = append(replay.Chain, *glued)
+		}
+	}
+
+	return replay
+}
+
+// Check compares the chain with a table given apart, like the mesa of a
+// request. Tables of the same length are compared bone by bone, otherwise
+// by the bones missing from each one.
+func (r TableReplay) Check(table []Domino) []Discrepancy {
+	var discrepancies []Discrepancy
+
+	if len(table) == len(r.Chain) {
+		for i := range table {
+			want, got := r.Chain[i], table[i]
+			if want == got {
+				continue
+			}
+
+			kind := DiscrepancyBone
+			if want.Equals(got) {
+				kind = DiscrepancyFlipped
+			}
+
+			discrepancies = append(discrepancies, Discrepancy{
+				Kind:     kind,
+				Play:     -1,
+				Position: i,
+				Want:     &want,
+				Got:      &got,
+			})
+		}
+
+		return discrepancies
+	}
+
+	discrepancies = append(discrepancies, Discrepancy{
+		Kind:     DiscrepancyLength,
+		Play:     -1,
+		Position: -1,
+	})
+
+	chain, other := BoneSetOf(r.Chain...), BoneSetOf(table...)
+	for _, bone := range r.Chain {
+		if !other.Has(bone) {
+			bone := bone
+			discrepancies = append(discrepancies, Discrepancy{
+				Kind:     DiscrepancyMissing,
+				Play:     -1,
+				Position: -1,
+				Want:     &bone,
+			})
+		}
+	}
+
+	for i, bone := range table {
+		if !chain.Has(bone) {
+			bone := bone
+			discrepancies = append(discrepancies, Discrepancy{
+				Kind:     DiscrepancyExtra,
+				Play:     -1,
+				Position: i,
+				Got:      &bone,
+			})
+		}
+	}
+
+	return discrepancies
+}
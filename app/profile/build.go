@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"github.com/josecleiton/domino/app/gamelog"
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+// Build learns a profile of every bot from the plays of its seats. Games
+// that can't be replayed are skipped.
+func Build(games []*gamelog.Game) Profiles {
+	profiles := Profiles{}
+
+	for _, g := range games {
+		decisions, err := g.Decisions()
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[string]bool, len(g.Bots))
+		for _, bot := range g.Bots {
+			if seen[bot] {
+				continue
+			}
+			seen[bot] = true
+
+			profile, ok := profiles[bot]
+			if !ok {
+				profile = &Profile{Bot: bot}
+				profiles[bot] = profile
+			}
+
+			profile.Games++
+		}
+
+		for _, decision := range decisions {
+			if decision.Play.Pass() {
+				continue
+			}
+
+			state := decision.State
+			s := NewSituation(
+				inference.History(state),
+				state.PlayerPosition,
+				models.BoneSetOf(state.Hand...),
+				state.Table[0].L,
+				state.Table[len(state.Table)-1].R,
+				false,
+			)
+
+			profiles[g.Bots[state.PlayerPosition-models.DominoMinPlayer]].observe(s, decision.Play.Bone.Domino)
+		}
+	}
+
+	return profiles
+}
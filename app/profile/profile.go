@@ -0,0 +1,268 @@
+package profile
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+// earlyTurns are the plays, after the first one, in which a double counts
+// as played early
+const earlyTurns = 3
+
+// minChances below which a rate is too noisy to bias anything
+const minChances = 20
+
+// Rate is how often a player picked a bone with a feature when it could
+// have picked one without it
+type Rate struct {
+	Chances int `json:"chances"`
+	Taken   int `json:"taken"`
+	// Expected is how many times a uniform choice would have taken it
+	Expected float64 `json:"expected"`
+}
+
+func (r *Rate) observe(has bool, with, options int) {
+	r.Chances++
+	r.Expected += float64(with) / float64(options)
+	if has {
+		r.Taken++
+	}
+}
+
+// Value is the rate smoothed towards a uniform choice
+func (r Rate) Value() float64 {
+	if r.Chances == 0 {
+		return 0
+	}
+
+	base := r.Expected / float64(r.Chances)
+
+	return (float64(r.Taken) + 2*base) / float64(r.Chances+2)
+}
+
+// Bias is how many times more often than a uniform choice the feature was
+// taken
+func (r Rate) Bias() float64 {
+	if r.Expected == 0 {
+		return 1
+	}
+
+	return float64(r.Taken) / r.Expected
+}
+
+type Preferences struct {
+	Heaviest Rate `json:"heaviest"`
+	Double   Rate `json:"double"`
+	Majority Rate `json:"majority"`
+}
+
+// Profile is how a bot picks its bones, learned from its logged games
+type Profile struct {
+	Bot       string `json:"bot"`
+	Games     int    `json:"games"`
+	Decisions int    `json:"decisions"`
+	// Opening is the first play of the bot that wasn't forced
+	Opening      Preferences `json:"opening"`
+	EarlyDoubles Rate        `json:"early_doubles"`
+	// Play are the later plays
+	Play         Preferences `json:"play"`
+	BlockPartner Rate        `json:"block_partner"`
+	PassPressure Rate        `json:"pass_pressure"`
+}
+
+// Profiles by bot name
+type Profiles map[string]*Profile
+
+func Load(name string) (Profiles, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := Profiles{}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func (p Profiles) Save(name string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
+
+type featureRate struct {
+	Feature Feature
+	Rate    *Rate
+}
+
+// rates of the features that matter in s
+func (p *Profile) rates(s Situation) []featureRate {
+	rates := make([]featureRate, 0, 5)
+
+	switch {
+	case s.Turn == 0:
+		rates = append(rates,
+			featureRate{FeatureHeaviest, &p.Opening.Heaviest},
+			featureRate{FeatureDouble, &p.Opening.Double},
+			featureRate{FeatureMajority, &p.Opening.Majority},
+		)
+	case s.Turn < earlyTurns:
+		rates = append(rates,
+			featureRate{FeatureHeaviest, &p.Play.Heaviest},
+			featureRate{FeatureDouble, &p.EarlyDoubles},
+			featureRate{FeatureMajority, &p.Play.Majority},
+		)
+	default:
+		rates = append(rates,
+			featureRate{FeatureHeaviest, &p.Play.Heaviest},
+			featureRate{FeatureDouble, &p.Play.Double},
+			featureRate{FeatureMajority, &p.Play.Majority},
+		)
+	}
+
+	if !s.Empty {
+		rates = append(rates,
+			featureRate{FeatureBlock, &p.BlockPartner},
+			featureRate{FeaturePressure, &p.PassPressure},
+		)
+	}
+
+	return rates
+}
+
+// options are the legal bones of a situation with their features
+type options struct {
+	Bones    [models.DominoMaxHandLength]models.Domino
+	Features [models.DominoMaxHandLength]Feature
+	Weights  [models.DominoMaxHandLength]float64
+	N        int
+	// With counts the bones with each feature, by Feature.index
+	With [featureCount]int
+}
+
+func newOptions(s Situation) options {
+	var o options
+	legal := s.Options()
+	heaviest, most := s.extremes(legal)
+
+	for rest := legal; !rest.Empty() && o.N < len(o.Bones); o.N++ {
+		var bone models.Domino
+		bone, rest = rest.Pop()
+
+		f := s.features(bone, heaviest, most)
+		o.Bones[o.N], o.Features[o.N] = bone, f
+		for feature := FeatureDouble; feature <= FeaturePressure; feature <<= 1 {
+			if f.Has(feature) {
+				o.With[feature.index()]++
+			}
+		}
+	}
+
+	return o
+}
+
+// mixed is whether some options have the feature and some don't
+func (o *options) mixed(feature Feature) bool {
+	with := o.With[feature.index()]
+	return with > 0 && with < o.N
+}
+
+// observe counts the features of the bone chosen in s
+func (p *Profile) observe(s Situation, chosen models.Domino) {
+	o := newOptions(s)
+	if o.N < 2 {
+		return
+	}
+
+	p.Decisions++
+
+	heaviest, most := s.extremes(s.Options())
+	picked := s.features(chosen, heaviest, most)
+	for _, r := range p.rates(s) {
+		if o.mixed(r.Feature) {
+			r.Rate.observe(picked.Has(r.Feature), o.With[r.Feature.index()], o.N)
+		}
+	}
+}
+
+// weigh the options, every rate tilts the uniform choice towards or away
+// from the bones with its feature
+func (p *Profile) weigh(s Situation) options {
+	o := newOptions(s)
+	for i := 0; i < o.N; i++ {
+		o.Weights[i] = 1
+	}
+
+	for _, r := range p.rates(s) {
+		if r.Rate.Chances < minChances || !o.mixed(r.Feature) {
+			continue
+		}
+
+		value := r.Rate.Value()
+		with := float64(o.With[r.Feature.index()])
+		for i := 0; i < o.N; i++ {
+			if o.Features[i].Has(r.Feature) {
+				o.Weights[i] *= value / with
+			} else {
+				o.Weights[i] *= (1 - value) / (float64(o.N) - with)
+			}
+		}
+	}
+
+	total := 0.0
+	for _, weight := range o.Weights[:o.N] {
+		total += weight
+	}
+
+	for i := 0; i < o.N; i++ {
+		o.Weights[i] /= total
+	}
+
+	return o
+}
+
+// Weights are the probabilities of the profile picking each legal bone of s
+func (p *Profile) Weights(s Situation) ([]models.Domino, []float64) {
+	o := p.weigh(s)
+
+	return o.Bones[:o.N], o.Weights[:o.N]
+}
+
+// Likelihood makes the profile an inference.Policy
+func (p *Profile) Likelihood(turns []inference.Turn, i int, hand models.BoneSet) float64 {
+	o := p.weigh(SituationAt(turns, i, hand))
+	for j := 0; j < o.N; j++ {
+		if o.Bones[j].Equals(*turns[i].Bone) {
+			return o.Weights[j]
+		}
+	}
+
+	return 0
+}
+
+// Choose draws a legal bone of s as the bot would
+func (p *Profile) Choose(s Situation, rng *rand.Rand) (models.Domino, bool) {
+	o := p.weigh(s)
+	if o.N == 0 {
+		return models.Domino{}, false
+	}
+
+	pick := rng.Float64()
+	for i := 0; i < o.N; i++ {
+		if pick -= o.Weights[i]; pick < 0 {
+			return o.Bones[i], true
+		}
+	}
+
+	return o.Bones[o.N-1], true
+}
@@ -0,0 +1,190 @@
+package profile
+
+import (
+	"math/bits"
+
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+// Feature is a trait of a bone a player may pick
+type Feature uint8
+
+const (
+	// FeatureDouble is a double
+	FeatureDouble Feature = 1 << iota
+	// FeatureHeaviest is among the legal bones with the highest pip sum
+	FeatureHeaviest
+	// FeatureMajority has the pip the hand has most of
+	FeatureMajority
+	// FeatureBlock covers the pip the partner just opened
+	FeatureBlock
+	// FeaturePressure leaves open a pip an opponent passed on
+	FeaturePressure
+
+	featureCount = iota
+)
+
+func (f Feature) Has(feature Feature) bool {
+	return f&feature != 0
+}
+
+func (f Feature) index() int {
+	return bits.TrailingZeros8(uint8(f))
+}
+
+// Situation is what a player faced when it had to pick a bone
+type Situation struct {
+	Player      models.PlayerPosition
+	Hand        models.BoneSet
+	Left, Right int
+	Empty       bool
+	// Turn counts the plays of the player before this one, the forced
+	// opening aside
+	Turn int
+	// PartnerPip is the pip the partner opened on its last turn, -1 when it
+	// didn't play
+	PartnerPip int
+	// Lacking are the pips the opponents are known not to have
+	Lacking models.PipSet
+}
+
+// NewSituation is the situation of player after turns, facing the open pips
+// left and right
+func NewSituation(
+	turns []inference.Turn,
+	player models.PlayerPosition,
+	hand models.BoneSet,
+	left, right int,
+	empty bool,
+) Situation {
+	s := Situation{
+		Player:     player,
+		Hand:       hand,
+		Left:       left,
+		Right:      right,
+		Empty:      empty,
+		PartnerPip: -1,
+	}
+
+	for _, turn := range turns {
+		if turn.Player == player && !turn.Pass() && !turn.Empty {
+			s.Turn++
+		}
+	}
+
+	unavailable := inference.Unavailable(turns)
+	for _, opponent := range []models.PlayerPosition{player.Add(1), player.Add(3)} {
+		s.Lacking |= unavailable[opponent-models.DominoMinPlayer]
+	}
+
+	// the seat two turns back is the partner
+	if n := len(turns); n >= 2 && turns[n-2].Player == player.Add(2) {
+		partner := turns[n-2]
+		if !partner.Pass() && !partner.Empty {
+			next := turns[n-1]
+			switch {
+			case next.Left != partner.Left:
+				s.PartnerPip = next.Left
+			case next.Right != partner.Right:
+				s.PartnerPip = next.Right
+			default:
+				s.PartnerPip = partner.Bone.L
+			}
+		}
+	}
+
+	return s
+}
+
+// SituationAt is the situation of the player of turns[i], holding hand
+func SituationAt(turns []inference.Turn, i int, hand models.BoneSet) Situation {
+	turn := turns[i]
+
+	return NewSituation(turns[:i], turn.Player, hand, turn.Left, turn.Right, turn.Empty)
+}
+
+// Options are the legal bones of the hand
+func (s Situation) Options() models.BoneSet {
+	if s.Empty {
+		return s.Hand
+	}
+
+	return s.Hand.Intersect(models.BonesWithPip(s.Left).Union(models.BonesWithPip(s.Right)))
+}
+
+// Features of bone among the legal bones
+func (s Situation) Features(bone models.Domino, options models.BoneSet) Feature {
+	heaviest, most := s.extremes(options)
+
+	return s.features(bone, heaviest, most)
+}
+
+// extremes are the highest pip sum of the options and the most bones of a
+// pip in the hand
+func (s Situation) extremes(options models.BoneSet) (int, int) {
+	heaviest := 0
+	for rest := options; !rest.Empty(); {
+		var option models.Domino
+		option, rest = rest.Pop()
+		heaviest = max(heaviest, option.Sum())
+	}
+
+	most := 0
+	for pips := s.Hand.Pips(); pips != 0; pips &= pips - 1 {
+		pip := bits.TrailingZeros16(uint16(pips))
+		most = max(most, s.Hand.Intersect(models.BonesWithPip(pip)).Len())
+	}
+
+	return heaviest, most
+}
+
+func (s Situation) features(bone models.Domino, heaviest, most int) Feature {
+	var features Feature
+
+	if bone.L == bone.R {
+		features |= FeatureDouble
+	}
+
+	if bone.Sum() == heaviest {
+		features |= FeatureHeaviest
+	}
+
+	if s.Hand.Intersect(models.BonesWithPip(bone.L)).Len() == most ||
+		s.Hand.Intersect(models.BonesWithPip(bone.R)).Len() == most {
+		features |= FeatureMajority
+	}
+
+	if s.Empty {
+		return features
+	}
+
+	if s.PartnerPip >= 0 && (s.Left == s.PartnerPip || s.Right == s.PartnerPip) &&
+		(bone.L == s.PartnerPip || bone.R == s.PartnerPip) {
+		features |= FeatureBlock
+	}
+
+	if s.Lacking != 0 {
+		if bone.L == s.Left || bone.R == s.Left {
+			if s.Lacking.Has(otherSide(bone, s.Left)) || s.Lacking.Has(s.Right) {
+				features |= FeaturePressure
+			}
+		}
+
+		if bone.L == s.Right || bone.R == s.Right {
+			if s.Lacking.Has(otherSide(bone, s.Right)) || s.Lacking.Has(s.Left) {
+				features |= FeaturePressure
+			}
+		}
+	}
+
+	return features
+}
+
+func otherSide(bone models.Domino, side int) int {
+	if bone.L == side {
+		return bone.R
+	}
+
+	return bone.L
+}
@@ -0,0 +1,187 @@
+package rating
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+const LedgerVersion = 1
+
+const (
+	InitialElo = 1500.0
+	EloK       = 16.0
+
+	// TrueSkill defaults, every entrant plays both seats of its team so a
+	// game is one against one
+	InitialMu    = 25.0
+	InitialSigma = InitialMu / 3
+	beta         = InitialSigma / 2
+	tau          = InitialSigma / 100
+)
+
+var ErrLedgerVersion = errors.New("unsupported ledger version")
+
+// Identity is what is rated: the same strategy on another commit or with
+// other parameters is another entrant
+type Identity struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit,omitempty"`
+	Params string `json:"params,omitempty"`
+}
+
+func (id Identity) String() string {
+	s := id.Name
+	if id.Commit != "" {
+		s += "@" + id.Commit
+	}
+
+	if id.Params != "" {
+		s += "[" + id.Params + "]"
+	}
+
+	return s
+}
+
+type Entry struct {
+	Identity
+	Elo     float64   `json:"elo"`
+	Mu      float64   `json:"mu"`
+	Sigma   float64   `json:"sigma"`
+	Games   int       `json:"games"`
+	Wins    int       `json:"wins"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Conservative is the TrueSkill the entry is 99% likely to be above
+func (e *Entry) Conservative() float64 {
+	return e.Mu - 3*e.Sigma
+}
+
+type Ledger struct {
+	Version int      `json:"version"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Load reads the ledger, a missing file is an empty ledger
+func Load(name string) (*Ledger, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Ledger{Version: LedgerVersion}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &Ledger{}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, err
+	}
+
+	if ledger.Version < 1 || ledger.Version > LedgerVersion {
+		return nil, fmt.Errorf("%w: %d", ErrLedgerVersion, ledger.Version)
+	}
+
+	return ledger, nil
+}
+
+func (l *Ledger) Save(name string) error {
+	l.Version = LedgerVersion
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
+
+// Entry of id, a new one starts at the initial ratings
+func (l *Ledger) Entry(id Identity) *Entry {
+	for _, entry := range l.Entries {
+		if entry.Identity == id {
+			return entry
+		}
+	}
+
+	now := time.Now().UTC()
+	entry := &Entry{
+		Identity: id,
+		Elo:      InitialElo,
+		Mu:       InitialMu,
+		Sigma:    InitialSigma,
+		Created:  now,
+		Updated:  now,
+	}
+	l.Entries = append(l.Entries, entry)
+
+	return entry
+}
+
+// Sorted entries, the most surely strong first
+func (l *Ledger) Sorted() []*Entry {
+	entries := append([]*Entry(nil), l.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Conservative() > entries[j].Conservative()
+	})
+
+	return entries
+}
+
+// Record a series of a against b, interleaving the wins of both so the
+// order of the games doesn't favor anyone
+func (l *Ledger) Record(a, b *Entry, winsA, winsB int) {
+	games := winsA + winsB
+	done := 0
+	for i := 1; i <= games; i++ {
+		// a wins its share of the first i games
+		if winsA*i/games > done {
+			done++
+			update(a, b)
+		} else {
+			update(b, a)
+		}
+	}
+
+	now := time.Now().UTC()
+	a.Updated, b.Updated = now, now
+}
+
+func update(winner, loser *Entry) {
+	winner.Games++
+	winner.Wins++
+	loser.Games++
+
+	expected := 1 / (1 + math.Pow(10, (loser.Elo-winner.Elo)/400))
+	winner.Elo += EloK * (1 - expected)
+	loser.Elo -= EloK * (1 - expected)
+
+	winnerVariance := winner.Sigma*winner.Sigma + tau*tau
+	loserVariance := loser.Sigma*loser.Sigma + tau*tau
+
+	c2 := 2*beta*beta + winnerVariance + loserVariance
+	c := math.Sqrt(c2)
+	t := (winner.Mu - loser.Mu) / c
+	v := pdf(t) / cdf(t)
+	w := v * (v + t)
+
+	winner.Mu += winnerVariance / c * v
+	loser.Mu -= loserVariance / c * v
+	winner.Sigma = math.Sqrt(winnerVariance * (1 - winnerVariance/c2*w))
+	loser.Sigma = math.Sqrt(loserVariance * (1 - loserVariance/c2*w))
+}
+
+func pdf(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func cdf(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
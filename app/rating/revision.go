@@ -0,0 +1,50 @@
+package rating
+
+import (
+	"os/exec"
+	"runtime/debug"
+	"strings"
+)
+
+const revisionLength = 7
+
+// Revision is the commit of the running build, from the build info or git,
+// with a +dirty suffix when the tree had changes
+func Revision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		revision, dirty := "", false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				dirty = setting.Value == "true"
+			}
+		}
+
+		if revision != "" {
+			return shortRevision(revision, dirty)
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+
+	status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+
+	return shortRevision(strings.TrimSpace(string(out)), err == nil && len(status) > 0)
+}
+
+func shortRevision(revision string, dirty bool) string {
+	if len(revision) > revisionLength {
+		revision = revision[:revisionLength]
+	}
+
+	if dirty {
+		revision += "+dirty"
+	}
+
+	return revision
+}
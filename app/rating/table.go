@@ -0,0 +1,30 @@
+package rating
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteTable writes the ratings of the entries, one per line
+func WriteTable(w io.Writer, entries []*Entry) {
+	width := len("entrant")
+	for _, entry := range entries {
+		width = max(width, len(entry.Identity.String()))
+	}
+
+	fmt.Fprintf(w, "%-*s %7s %6s %6s %12s %6s %16s\n", width, "entrant", "elo", "mu", "sigma", "conservative", "games", "updated")
+	for _, entry := range entries {
+		fmt.Fprintf(
+			w,
+			"%-*s %7.1f %6.2f %6.2f %12.2f %6d %16s\n",
+			width,
+			entry.Identity.String(),
+			entry.Elo,
+			entry.Mu,
+			entry.Sigma,
+			entry.Conservative(),
+			entry.Games,
+			entry.Updated.Format("2006-01-02 15:04"),
+		)
+	}
+}
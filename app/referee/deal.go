@@ -0,0 +1,78 @@
+package referee
+
+import (
+	"math/rand"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+type Hands [models.DominoMaxPlayer][]models.Domino
+
+// Deal double-six
+func Deal(rng *rand.Rand) Hands {
+	return DealSet(rng, models.DoubleSix)
+}
+
+// DealSet shuffles set and deals its hand length to every seat, the bones
+// left sleep
+func DealSet(rng *rand.Rand, set models.DominoSet) Hands {
+	bones := set.Dominoes()
+
+	// same Fisher-Yates shuffle used by run_domino.js
+	for i := len(bones) - 1; i > 0; i-- {
+		j := rng.Intn(i + 1)
+		bones[i], bones[j] = bones[j], bones[i]
+	}
+
+	var hands Hands
+	for i := range hands {
+		hand := bones[i*set.HandLength() : (i+1)*set.HandLength()]
+		hands[i] = append([]models.Domino{}, hand...)
+	}
+
+	return hands
+}
+
+func (h Hands) Of(player models.PlayerPosition) []models.Domino {
+	return h[player-models.DominoMinPlayer]
+}
+
+func (h Hands) Copy() Hands {
+	var hands Hands
+	for i, hand := range h {
+		hands[i] = append([]models.Domino{}, hand...)
+	}
+
+	return hands
+}
+
+// Bones dealt to every seat
+func (h Hands) Bones() models.BoneSet {
+	var bones models.BoneSet
+	for _, hand := range h {
+		bones = bones.Union(models.BoneSetOf(hand...))
+	}
+
+	return bones
+}
+
+func (h Hands) Holder(domino models.Domino) (models.PlayerPosition, bool) {
+	for i, hand := range h {
+		for _, bone := range hand {
+			if bone.Equals(domino) {
+				return models.PlayerPosition(i + models.DominoMinPlayer), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func handSum(hand []models.Domino) int {
+	sum := 0
+	for _, bone := range hand {
+		sum += bone.Sum()
+	}
+
+	return sum
+}
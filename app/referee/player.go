@@ -0,0 +1,193 @@
+package referee
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+const (
+	leftSide  = "esquerda"
+	rightSide = "direita"
+)
+
+// headers of controllers.GameHandler with the goal of the match
+const (
+	rulesHeader = "X-Rules"
+	scoreHeader = "X-Score"
+)
+
+type Player interface {
+	Play(state *models.DominoGameState) (models.DominoPlayWithPass, error)
+}
+
+type PlayerFunc func(state *models.DominoGameState) (models.DominoPlayWithPass, error)
+
+func (f PlayerFunc) Play(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	return f(state)
+}
+
+// HandlerPlayer drives an http.Handler in-process, e.g. controllers.GameHandler
+type HandlerPlayer struct {
+	Handler http.Handler
+}
+
+// HTTPPlayer talks to a bot endpoint the same way run_domino.js does
+type HTTPPlayer struct {
+	URL    string
+	Client *http.Client
+}
+
+type gameStateRequest struct {
+	Player int           `json:"jogador"`
+	Hand   []string      `json:"mao"`
+	Table  []string      `json:"mesa"`
+	Plays  []playRequest `json:"jogadas"`
+}
+
+type playRequest struct {
+	Player    int    `json:"jogador"`
+	Bone      string `json:"pedra"`
+	Direction string `json:"lado,omitempty"`
+}
+
+type playResponse struct {
+	Bone      string `json:"pedra"`
+	Direction string `json:"lado"`
+}
+
+func (p HandlerPlayer) Play(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	body, err := encodeState(state)
+	if err != nil {
+		return models.DominoPlayWithPass{}, err
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	setGoal(req.Header, state.Goal)
+	rec := httptest.NewRecorder()
+
+	p.Handler.ServeHTTP(rec, req)
+
+	return decodePlay(state.PlayerPosition, rec.Code, rec.Body)
+}
+
+func (p HTTPPlayer) Play(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	body, err := encodeState(state)
+	if err != nil {
+		return models.DominoPlayWithPass{}, err
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return models.DominoPlayWithPass{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setGoal(req.Header, state.Goal)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return models.DominoPlayWithPass{}, err
+	}
+	defer resp.Body.Close()
+
+	return decodePlay(state.PlayerPosition, resp.StatusCode, resp.Body)
+}
+
+func setGoal(header http.Header, goal *models.Goal) {
+	if goal == nil {
+		return
+	}
+
+	header.Set(rulesHeader, goal.Rules.String())
+	header.Set(scoreHeader, goal.ScoreString())
+}
+
+func encodeState(state *models.DominoGameState) ([]byte, error) {
+	request := gameStateRequest{
+		Player: int(state.PlayerPosition),
+		Hand:   make([]string, 0, len(state.Hand)),
+		Table:  make([]string, 0, len(state.Table)),
+		Plays:  make([]playRequest, 0, len(state.Plays)),
+	}
+
+	for _, bone := range state.Hand {
+		request.Hand = append(request.Hand, models.DominoToString(bone))
+	}
+
+	for _, bone := range state.Table {
+		request.Table = append(request.Table, models.DominoToString(bone))
+	}
+
+	for i, play := range state.Plays {
+		p := playRequest{
+			Player: int(play.PlayerPosition),
+			Bone:   models.DominoToString(play.Bone.Domino),
+		}
+
+		if i > 0 {
+			p.Direction = sideName(play.Bone.Edge)
+		}
+
+		request.Plays = append(request.Plays, p)
+	}
+
+	return json.Marshal(request)
+}
+
+func decodePlay(
+	player models.PlayerPosition,
+	status int,
+	body io.Reader,
+) (models.DominoPlayWithPass, error) {
+	if status != http.StatusOK {
+		return models.DominoPlayWithPass{}, fmt.Errorf("unexpected status %d", status)
+	}
+
+	var resp playResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return models.DominoPlayWithPass{}, err
+	}
+
+	play := models.DominoPlayWithPass{PlayerPosition: player}
+	if resp.Bone == "" {
+		return play, nil
+	}
+
+	domino, err := models.DominoFromString(resp.Bone)
+	if err != nil {
+		return play, err
+	}
+
+	var edge models.Edge
+	switch resp.Direction {
+	case leftSide:
+		edge = models.LeftEdge
+	case rightSide:
+		edge = models.RightEdge
+	default:
+		return play, fmt.Errorf("%w: %q", ErrInvalidEdge, resp.Direction)
+	}
+
+	play.Bone = &models.DominoInTable{Edge: edge, Domino: *domino}
+
+	return play, nil
+}
+
+func sideName(edge models.Edge) string {
+	if edge == models.RightEdge {
+		return rightSide
+	}
+
+	return leftSide
+}
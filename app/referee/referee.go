@@ -0,0 +1,421 @@
+package referee
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+const DefaultTimeout = 3 * time.Second
+
+type Team int
+
+const (
+	FirstTeam  Team = iota + 1 // players 1 and 3
+	SecondTeam                 // players 2 and 4
+)
+
+type Outcome int
+
+const (
+	OutcomeDomino Outcome = iota + 1
+	OutcomeClosed
+	OutcomeDisqualified
+)
+
+var (
+	ErrTimeout      = errors.New("player exceeded the time limit")
+	ErrIllegalPass  = errors.New("player passed holding a playable bone")
+	ErrNotInHand    = errors.New("bone is not in the player hand")
+	ErrNotGlueable  = errors.New("bone does not glue to the table edge")
+	ErrInvalidEdge  = errors.New("invalid table edge")
+	ErrPlayerPanics = errors.New("player panicked")
+	ErrPlayerFailed = errors.New("player failed with an error")
+)
+
+type Match struct {
+	Players [models.DominoMaxPlayer]Player
+	// Timeout per play, zero disables it
+	Timeout time.Duration
+	// Log receives the same narration run_domino.js prints
+	Log io.Writer
+	// Rules of the match, the README ones by default
+	Rules models.Ruleset
+}
+
+type Result struct {
+	Winner  Team
+	Outcome Outcome
+	// Player who emptied the hand, played the closing bone or got disqualified
+	Player models.PlayerPosition
+	Err    error
+	// Batida is how the hand was won, BatidaNone when it wasn't by domino
+	Batida models.Batida
+	// Score of the winners by the rules of the match
+	Score int
+	Deal  Hands
+	Hands Hands
+	Plays []models.DominoPlay
+	Turns []models.DominoPlayWithPass
+}
+
+type match struct {
+	Match
+	// points of the teams before the hand
+	points [2]int
+	hands  Hands
+	table  []models.Domino
+	plays  []models.DominoPlay
+	turns  []models.DominoPlayWithPass
+}
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeDomino:
+		return "domino"
+	case OutcomeClosed:
+		return "closed"
+	case OutcomeDisqualified:
+		return "disqualified"
+	}
+
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+func TeamOf(player models.PlayerPosition) Team {
+	if player%2 == 1 {
+		return FirstTeam
+	}
+
+	return SecondTeam
+}
+
+func (t Team) Other() Team {
+	if t == FirstTeam {
+		return SecondTeam
+	}
+
+	return FirstTeam
+}
+
+func (r Result) Points(team Team) int {
+	points := 0
+	for i, hand := range r.Hands {
+		if TeamOf(models.PlayerPosition(i+models.DominoMinPlayer)) == team {
+			points += handSum(hand)
+		}
+	}
+
+	return points
+}
+
+// Run plays a single hand
+func (m Match) Run(deal Hands) Result {
+	return m.run(deal, [2]int{})
+}
+
+func (m Match) run(deal Hands, points [2]int) Result {
+	ma := &match{
+		Match:  m,
+		points: points,
+		hands:  deal.Copy(),
+		table:  make([]models.Domino, 0, models.DominoLength),
+		plays:  make([]models.DominoPlay, 0, models.DominoLength),
+		turns:  make([]models.DominoPlayWithPass, 0, models.DominoLength*2),
+	}
+
+	result := ma.run()
+	result.Deal = deal.Copy()
+	result.Hands = ma.hands
+	result.Plays = ma.plays
+	result.Turns = ma.turns
+
+	if result.Outcome != OutcomeDisqualified {
+		result.Score = m.Rules.Points(result.Batida, result.Points(result.Winner.Other()))
+	}
+
+	return result
+}
+
+func (m *match) run() Result {
+	m.logf("Pedras distribuídas:\n")
+	for i := range m.hands {
+		m.logf("  Jogador %d: %s\n", i+models.DominoMinPlayer, handString(m.hands[i]))
+	}
+
+	opening, ok := m.Rules.Opening(m.hands.Bones())
+	if !ok {
+		panic("referee: no player holds the opening bone")
+	}
+
+	current, _ := m.hands.Holder(opening)
+
+	m.place(current, models.DominoInTable{Edge: models.LeftEdge, Domino: opening})
+	m.logf(
+		"Jogador %d começa a partida e coloca a pedra [%s] na mesa.\n\n",
+		current,
+		models.DominoToString(opening),
+	)
+
+	for passes := 0; passes < models.DominoMaxPlayer; {
+		current = current.Next()
+
+		play, err := m.play(current)
+		if err == nil {
+			err = m.validate(current, play)
+		}
+
+		if err != nil {
+			m.logf("Jogador %d foi desclassificado: %s.\n\n", current, err)
+			return Result{
+				Winner:  TeamOf(current).Other(),
+				Outcome: OutcomeDisqualified,
+				Player:  current,
+				Err:     err,
+			}
+		}
+
+		if play.Pass() {
+			m.turns = append(m.turns, models.DominoPlayWithPass{PlayerPosition: current})
+			m.logf("Jogador %d passou a vez.\n\n", current)
+			passes++
+			continue
+		}
+
+		passes = 0
+		left, right := m.table[0].L, m.table[len(m.table)-1].R
+		glued := m.place(current, *play.Bone)
+		m.logf(
+			"Jogador %d jogou a pedra [%s] no lado %s da mesa.\n\n",
+			current,
+			models.DominoToString(glued.Domino),
+			sideName(glued.Edge),
+		)
+
+		if len(m.hands.Of(current)) == 0 {
+			m.logf("Jogador %d ganhou a partida!\n\n", current)
+			return Result{
+				Winner:  TeamOf(current),
+				Outcome: OutcomeDomino,
+				Player:  current,
+				Batida:  models.BatidaOf(glued.Domino, left, right),
+			}
+		}
+	}
+
+	return m.closed()
+}
+
+func (m *match) closed() Result {
+	m.logf("Todos os jogadores passaram a vez e a partida terminou empatada.\n\n")
+
+	for i, hand := range m.hands {
+		m.logf("  Jogador %d: %d pontos.\n", i+models.DominoMinPlayer, handSum(hand))
+	}
+
+	result := Result{Outcome: OutcomeClosed, Player: m.plays[len(m.plays)-1].PlayerPosition}
+	result.Hands = m.hands
+
+	first, second := result.Points(FirstTeam), result.Points(SecondTeam)
+	switch {
+	case first < second:
+		result.Winner = FirstTeam
+		m.logf(
+			"Jogadores 1 e 3 ganharam com %d pontos contra %d pontos dos jogadores 2 e 4.\n\n",
+			first,
+			second,
+		)
+	case first > second:
+		result.Winner = SecondTeam
+		m.logf(
+			"Jogadores 2 e 4 ganharam com %d pontos contra %d pontos dos jogadores 1 e 3.\n\n",
+			second,
+			first,
+		)
+	default:
+		// whoever closed the game loses the tie
+		result.Winner = TeamOf(result.Player).Other()
+		m.logf(
+			"As duas equipes tem a mesma quantidade de pontos. Jogador %d foi o último a jogar perde a partida.\n\n",
+			result.Player,
+		)
+	}
+
+	return result
+}
+
+func (m *match) play(player models.PlayerPosition) (play models.DominoPlayWithPass, err error) {
+	state := m.state(player)
+	p := m.Players[player-models.DominoMinPlayer]
+
+	call := func() (play models.DominoPlayWithPass, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%w: %v", ErrPlayerPanics, r)
+			}
+		}()
+
+		return p.Play(state)
+	}
+
+	if m.Timeout <= 0 {
+		return call()
+	}
+
+	type reply struct {
+		play models.DominoPlayWithPass
+		err  error
+	}
+
+	replies := make(chan reply, 1)
+	go func() {
+		play, err := call()
+		replies <- reply{play, err}
+	}()
+
+	timer := time.NewTimer(m.Timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-replies:
+		return r.play, r.err
+	case <-timer.C:
+		return models.DominoPlayWithPass{}, ErrTimeout
+	}
+}
+
+func (m *match) validate(player models.PlayerPosition, play models.DominoPlayWithPass) error {
+	hand := m.hands.Of(player)
+
+	if play.Pass() {
+		for _, edge := range m.edges() {
+			for _, bone := range hand {
+				if edge.Glue(bone) != nil {
+					return fmt.Errorf("%w: %s", ErrIllegalPass, models.DominoToString(bone))
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if indexOf(hand, play.Bone.Domino) == -1 {
+		return fmt.Errorf("%w: %s", ErrNotInHand, models.DominoToString(play.Bone.Domino))
+	}
+
+	edge, ok := m.edge(play.Bone.Edge)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidEdge, play.Bone.Edge)
+	}
+
+	if edge.Glue(play.Bone.Domino) == nil {
+		return fmt.Errorf(
+			"%w: %s on %s",
+			ErrNotGlueable,
+			models.DominoToString(play.Bone.Domino),
+			play.Bone.Edge,
+		)
+	}
+
+	return nil
+}
+
+func (m *match) place(player models.PlayerPosition, bone models.DominoInTable) models.DominoInTable {
+	hand := m.hands.Of(player)
+	idx := indexOf(hand, bone.Domino)
+	m.hands[player-models.DominoMinPlayer] = append(hand[:idx:idx], hand[idx+1:]...)
+
+	glued := bone
+	if len(m.table) == 0 {
+		m.table = append(m.table, bone.Domino)
+	} else {
+		edge, _ := m.edge(bone.Edge)
+		glued.Domino = *edge.Glue(bone.Domino)
+
+		if bone.Edge == models.LeftEdge {
+			m.table = append([]models.Domino{glued.Domino}, m.table...)
+		} else {
+			m.table = append(m.table, glued.Domino)
+		}
+	}
+
+	play := models.DominoPlay{PlayerPosition: player, Bone: glued}
+	m.plays = append(m.plays, play)
+	m.turns = append(m.turns, models.DominoPlayWithPass{
+		PlayerPosition: player,
+		Bone:           &play.Bone,
+	})
+
+	return glued
+}
+
+func (m *match) state(player models.PlayerPosition) *models.DominoGameState {
+	state := &models.DominoGameState{
+		PlayerPosition: player,
+		Hand:           append([]models.Domino{}, m.hands.Of(player)...),
+		Table:          append([]models.Domino{}, m.table...),
+		TableMap:       models.TableMapFromDominoes(m.table),
+		Plays:          append([]models.DominoPlay{}, m.plays...),
+	}
+
+	// the README rules go without a goal, like run_domino.js
+	if m.Rules != models.ClassicRules {
+		team := TeamOf(player)
+		state.Goal = &models.Goal{
+			Rules:   m.Rules,
+			Points:  m.points[team-FirstTeam],
+			Against: m.points[team.Other()-FirstTeam],
+		}
+	}
+
+	return state
+}
+
+func (m *match) edge(edge models.Edge) (models.DominoInTable, bool) {
+	switch edge {
+	case models.LeftEdge:
+		return models.DominoInTable{Edge: edge, Domino: m.table[0]}, true
+	case models.RightEdge:
+		return models.DominoInTable{Edge: edge, Domino: m.table[len(m.table)-1]}, true
+	}
+
+	return models.DominoInTable{}, false
+}
+
+func (m *match) edges() []models.DominoInTable {
+	left, _ := m.edge(models.LeftEdge)
+	right, _ := m.edge(models.RightEdge)
+
+	return []models.DominoInTable{left, right}
+}
+
+func (m Match) logf(format string, args ...any) {
+	if m.Log == nil {
+		return
+	}
+
+	fmt.Fprintf(m.Log, format, args...)
+}
+
+func indexOf(hand []models.Domino, domino models.Domino) int {
+	for i, bone := range hand {
+		if bone.Equals(domino) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func handString(hand []models.Domino) string {
+	bones := make([]string, 0, len(hand))
+	for _, bone := range hand {
+		bones = append(bones, "["+models.DominoToString(bone)+"]")
+	}
+
+	return strings.Join(bones, " ")
+}
@@ -0,0 +1,49 @@
+package referee
+
+import "github.com/josecleiton/domino/app/models"
+
+// Series is a match of many hands, played until a team scores the target of
+// the rules
+type Series struct {
+	Winner Team
+	// Points of FirstTeam and SecondTeam
+	Points [2]int
+	Hands  []Result
+}
+
+// RunSeries plays the hands deal gives until a team wins, a disqualification
+// loses the whole series. Without a target it's a single hand.
+func (m Match) RunSeries(deal func() Hands) Series {
+	var series Series
+
+	for {
+		result := m.run(deal(), series.Points)
+		series.Hands = append(series.Hands, result)
+
+		if result.Outcome == OutcomeDisqualified {
+			series.Winner = result.Winner
+			return series
+		}
+
+		series.Points[result.Winner-FirstTeam] += result.Score
+		if result.Batida != models.BatidaNone {
+			m.logf("A batida foi %s e valeu %d pontos.\n", result.Batida, result.Score)
+		}
+
+		if m.Rules.Over(series.Points[result.Winner-FirstTeam]) {
+			series.Winner = result.Winner
+			return series
+		}
+
+		m.logf(
+			"Placar: jogadores 1 e 3 %d x %d jogadores 2 e 4.\n\n",
+			series.Points[0],
+			series.Points[1],
+		)
+	}
+}
+
+// Last hand of the series
+func (s Series) Last() Result {
+	return s.Hands[len(s.Hands)-1]
+}
@@ -0,0 +1,208 @@
+package significance
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Confidence of the reported intervals
+const Confidence = 0.95
+
+// Score is a series of games of a side against another
+type Score struct {
+	Wins, Losses int
+}
+
+func (s Score) Games() int {
+	return s.Wins + s.Losses
+}
+
+func (s Score) WinRate() float64 {
+	if s.Games() == 0 {
+		return 0
+	}
+
+	return float64(s.Wins) / float64(s.Games())
+}
+
+// Interval is the Wilson score interval of the win rate
+func (s Score) Interval(confidence float64) (float64, float64) {
+	n := float64(s.Games())
+	if n == 0 {
+		return 0, 1
+	}
+
+	z := distuv.UnitNormal.Quantile(1 - (1-confidence)/2)
+	p := s.WinRate()
+
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// PValue of the two-sided exact binomial test of both sides being equally
+// strong
+func (s Score) PValue() float64 {
+	n := s.Games()
+	if n == 0 {
+		return 1
+	}
+
+	binomial := distuv.Binomial{N: float64(n), P: 0.5}
+	tail := min(s.Wins, s.Losses)
+
+	return math.Min(1, 2*binomial.CDF(float64(tail)))
+}
+
+// Decision of a sequential test
+type Decision int
+
+const (
+	Continue Decision = iota
+	// AcceptH0 is no better than the null hypothesis
+	AcceptH0
+	// AcceptH1 is better by the alternative hypothesis
+	AcceptH1
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Continue:
+		return "continue"
+	case AcceptH0:
+		return "H0"
+	case AcceptH1:
+		return "H1"
+	}
+
+	return fmt.Sprintf("Decision(%d)", int(d))
+}
+
+// SPRT is Wald's sequential probability ratio test of the win rate being
+// P0 against it being P1, wrong at most Alpha and Beta of the times
+type SPRT struct {
+	P0, P1      float64
+	Alpha, Beta float64
+}
+
+// DefaultSPRT tells a side that wins 55% of the games from an equal one
+var DefaultSPRT = SPRT{P0: 0.5, P1: 0.55, Alpha: 0.05, Beta: 0.05}
+
+// LLR is the log likelihood ratio of the score under P1 and P0
+func (t SPRT) LLR(s Score) float64 {
+	return float64(s.Wins)*math.Log(t.P1/t.P0) + float64(s.Losses)*math.Log((1-t.P1)/(1-t.P0))
+}
+
+// Bounds of the LLR under which H0 and over which H1 is accepted
+func (t SPRT) Bounds() (float64, float64) {
+	return math.Log(t.Beta / (1 - t.Alpha)), math.Log((1 - t.Beta) / t.Alpha)
+}
+
+func (t SPRT) Decide(s Score) Decision {
+	lower, upper := t.Bounds()
+
+	switch llr := t.LLR(s); {
+	case llr >= upper:
+		return AcceptH1
+	case llr <= lower:
+		return AcceptH0
+	}
+
+	return Continue
+}
+
+// Verdict of a pairing, testing each side for being the stronger one
+type Verdict int
+
+const (
+	Undecided Verdict = iota
+	// Stronger is the first side, Weaker the second one
+	Stronger
+	Weaker
+	// Equivalent sides are closer than the SPRT tells apart
+	Equivalent
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Undecided:
+		return "undecided"
+	case Stronger:
+		return "stronger"
+	case Weaker:
+		return "weaker"
+	case Equivalent:
+		return "equivalent"
+	}
+
+	return fmt.Sprintf("Verdict(%d)", int(v))
+}
+
+// Verdict runs the SPRT for both sides of the score
+func (t SPRT) Verdict(s Score) Verdict {
+	first := t.Decide(s)
+	second := t.Decide(Score{Wins: s.Losses, Losses: s.Wins})
+
+	switch {
+	case first == AcceptH1:
+		return Stronger
+	case second == AcceptH1:
+		return Weaker
+	case first == AcceptH0 && second == AcceptH0:
+		return Equivalent
+	}
+
+	return Undecided
+}
+
+// Remaining estimates how many more games the SPRT of the side ahead
+// needs to decide, from the current win rate. It's -1 when the score gives
+// no hint of either.
+func (t SPRT) Remaining(s Score) int {
+	if s.Losses > s.Wins {
+		s = Score{Wins: s.Losses, Losses: s.Wins}
+	}
+
+	if t.Decide(s) != Continue {
+		return 0
+	}
+
+	p := s.WinRate()
+	drift := p*math.Log(t.P1/t.P0) + (1-p)*math.Log((1-t.P1)/(1-t.P0))
+	if s.Games() == 0 || drift == 0 {
+		return -1
+	}
+
+	lower, upper := t.Bounds()
+	bound := upper
+	if drift < 0 {
+		bound = lower
+	}
+
+	return int(math.Ceil((bound - t.LLR(s)) / drift))
+}
+
+// Report sums up the significance of a score in a line
+func Report(s Score, test SPRT) string {
+	lower, upper := s.Interval(Confidence)
+
+	report := fmt.Sprintf(
+		"win rate %.4f [%.4f, %.4f] p=%.4g sprt %s",
+		s.WinRate(),
+		lower,
+		upper,
+		s.PValue(),
+		test.Verdict(s),
+	)
+
+	if test.Verdict(s) == Undecided {
+		if remaining := test.Remaining(s); remaining > 0 {
+			report += fmt.Sprintf(", ~%d more games", remaining)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,132 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+// DuplicateStats are the stats of a duplicate run, the luck of each deal
+// cancels out because both sides play it from the same seats
+type DuplicateStats struct {
+	Stats
+	Deals int
+	// Sweeps are the deals the player won from every seating, Swept the ones
+	// it lost from every seating
+	Sweeps int
+	Swept  int
+	// Scores has the share of the games of each deal the player won
+	Scores []float64
+}
+
+// StdErr of the win rate, from the spread of the deal scores
+func (s DuplicateStats) StdErr() float64 {
+	n := len(s.Scores)
+	if n < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, score := range s.Scores {
+		mean += score
+	}
+	mean /= float64(n)
+
+	variance := 0.0
+	for _, score := range s.Scores {
+		variance += (score - mean) * (score - mean)
+	}
+	variance /= float64(n - 1)
+
+	return math.Sqrt(variance / float64(n))
+}
+
+// StdErr of the win rate if every game had its own deal
+func (s Stats) StdErr() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+
+	p := s.WinRate()
+
+	return math.Sqrt(p * (1 - p) / float64(s.Games))
+}
+
+// RunDuplicate plays every deal seeded by seed twice, player and opponent
+// swapping seats, a deal being every hand of a match under rules. With
+// seatings the hands are also dealt in every order around the table that
+// makes a different game, 12 games per deal: turning the whole table only
+// moves the opening with the hands and replays the same game.
+func RunDuplicate(deals int, seed int64, rules models.Ruleset, player, opponent referee.Player, seatings bool) DuplicateStats {
+	rng := rand.New(rand.NewSource(seed))
+	stats := DuplicateStats{Scores: make([]float64, 0, deals)}
+
+	orders := [][models.DominoMaxPlayer]int{{0, 1, 2, 3}}
+	if seatings {
+		orders = seatingOrders()
+	}
+
+	for i := 0; i < deals; i++ {
+		dealSeed := rng.Int63()
+		wins, games := stats.Wins, stats.Games
+
+		for _, order := range orders {
+			for _, team := range []referee.Team{referee.FirstTeam, referee.SecondTeam} {
+				// every game of the deal is dealt the same hands
+				dealer := rand.New(rand.NewSource(dealSeed))
+				deal := func() referee.Hands {
+					return arrange(referee.DealSet(dealer, rules.Set), order)
+				}
+
+				match := referee.Match{Rules: rules}
+				for seat := range match.Players {
+					position := models.PlayerPosition(seat + models.DominoMinPlayer)
+					if referee.TeamOf(position) == team {
+						match.Players[seat] = player
+					} else {
+						match.Players[seat] = opponent
+					}
+				}
+
+				stats.addSeries(team, match.RunSeries(deal))
+			}
+		}
+
+		won, played := stats.Wins-wins, stats.Games-games
+		switch won {
+		case played:
+			stats.Sweeps++
+		case 0:
+			stats.Swept++
+		}
+
+		stats.Deals++
+		stats.Scores = append(stats.Scores, float64(won)/float64(played))
+	}
+
+	return stats
+}
+
+// seatingOrders keeps the first hand on the first seat and puts the other
+// three in every order, any two of them differ in who plays after whom
+// wherever the opening is
+func seatingOrders() [][models.DominoMaxPlayer]int {
+	orders := make([][models.DominoMaxPlayer]int, 0, 6)
+	for _, rest := range [][3]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}} {
+		orders = append(orders, [models.DominoMaxPlayer]int{0, rest[0], rest[1], rest[2]})
+	}
+
+	return orders
+}
+
+// arrange deals to each seat the hand order has for it
+func arrange(deal referee.Hands, order [models.DominoMaxPlayer]int) referee.Hands {
+	var seated referee.Hands
+	for i, hand := range order {
+		seated[i] = deal[hand]
+	}
+
+	return seated
+}
@@ -0,0 +1,176 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+// Stats of the games of a player, Domino, Closed and Disqualified count the
+// hands when the games are matches of many hands
+type Stats struct {
+	Games        int
+	Wins         int
+	Losses       int
+	Domino       int
+	Closed       int
+	Disqualified int
+	// Errors holds the reason of every disqualification of the evaluated player
+	Errors []error
+}
+
+// GamePlayer calls game.Play directly, no HTTP involved
+var GamePlayer = referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), game.PlayTimeout)
+	defer cancel()
+
+	return game.Play(ctx, state), nil
+})
+
+// StrategyPlayer plays with a registered game strategy, keeping its own
+// sessions
+func StrategyPlayer(name string) (referee.Player, error) {
+	return ProfiledStrategyPlayer(name, nil)
+}
+
+// ProfiledStrategyPlayer is a StrategyPlayer told that its opponents play
+// like opponent
+func ProfiledStrategyPlayer(name string, opponent *profile.Profile) (referee.Player, error) {
+	if !game.HasStrategy(name) {
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+
+	sessions := game.NewSessions()
+
+	return referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), game.PlayTimeout)
+		defer cancel()
+
+		session := sessions.Session("", state)
+		session.SetOpponent(opponent)
+
+		return session.PlayStrategy(ctx, name, state), nil
+	}), nil
+}
+
+// ProfilePlayer imitates the bot of the profile
+func ProfilePlayer(bot *profile.Profile, rng *rand.Rand) referee.Player {
+	return referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+		plays := state.LegalPlays()
+		if len(plays) == 0 {
+			return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}, nil
+		}
+
+		s := profile.NewSituation(
+			inference.History(state),
+			state.PlayerPosition,
+			models.BoneSetOf(state.Hand...),
+			0,
+			0,
+			len(state.Table) == 0,
+		)
+		if !s.Empty {
+			s.Left, s.Right = state.Table[0].L, state.Table[len(state.Table)-1].R
+		}
+
+		bone, ok := bot.Choose(s, rng)
+		if ok {
+			for _, play := range plays {
+				if play.Bone.Domino.Equals(bone) {
+					return play, nil
+				}
+			}
+		}
+
+		return plays[rng.Intn(len(plays))], nil
+	})
+}
+
+func RandomPlayer(rng *rand.Rand) referee.Player {
+	return referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+		plays := state.LegalPlays()
+		if len(plays) == 0 {
+			return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}, nil
+		}
+
+		return plays[rng.Intn(len(plays))], nil
+	})
+}
+
+func (s Stats) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+
+	return float64(s.Wins) / float64(s.Games)
+}
+
+// Run plays games deals seeded by seed, player against opponent, swapping
+// the seats of the teams every other deal
+func Run(games int, seed int64, player, opponent referee.Player) Stats {
+	return RunRules(games, seed, models.ClassicRules, player, opponent)
+}
+
+// RunRules is Run with every game a match under rules, dealt from seed
+func RunRules(games int, seed int64, rules models.Ruleset, player, opponent referee.Player) Stats {
+	rng := rand.New(rand.NewSource(seed))
+	deal := func() referee.Hands {
+		return referee.DealSet(rng, rules.Set)
+	}
+	stats := Stats{}
+
+	for i := 0; i < games; i++ {
+		team := referee.FirstTeam
+		if i%2 == 1 {
+			team = referee.SecondTeam
+		}
+
+		match := referee.Match{Rules: rules}
+		for seat := range match.Players {
+			position := models.PlayerPosition(seat + models.DominoMinPlayer)
+			if referee.TeamOf(position) == team {
+				match.Players[seat] = player
+			} else {
+				match.Players[seat] = opponent
+			}
+		}
+
+		stats.addSeries(team, match.RunSeries(deal))
+	}
+
+	return stats
+}
+
+func (s *Stats) addSeries(team referee.Team, series referee.Series) {
+	s.Games++
+
+	if series.Winner == team {
+		s.Wins++
+	} else {
+		s.Losses++
+	}
+
+	for _, result := range series.Hands {
+		s.addHand(team, result)
+	}
+}
+
+func (s *Stats) addHand(team referee.Team, result referee.Result) {
+	switch result.Outcome {
+	case referee.OutcomeDomino:
+		s.Domino++
+	case referee.OutcomeClosed:
+		s.Closed++
+	case referee.OutcomeDisqualified:
+		s.Disqualified++
+		if referee.TeamOf(result.Player) == team {
+			s.Errors = append(s.Errors, result.Err)
+		}
+	}
+}
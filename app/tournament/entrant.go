@@ -0,0 +1,71 @@
+package tournament
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/josecleiton/domino/app/controllers"
+	"github.com/josecleiton/domino/app/profile"
+	"github.com/josecleiton/domino/app/referee"
+	"github.com/josecleiton/domino/app/simulator"
+)
+
+const (
+	// HandlerEntrant is this bot as served, controllers.GameHandler in-process
+	HandlerEntrant = "handler"
+	RandomEntrant  = "random"
+	// ProfilePrefix imitates a bot of the profiles, e.g. profile:renato
+	ProfilePrefix = "profile:"
+)
+
+// ParseEntrant reads an entrant spec: a strategy name, a bot URL, handler,
+// random or profile:<bot>, optionally named as name=spec
+func ParseEntrant(spec string, profiles profile.Profiles) (Entrant, error) {
+	name := spec
+	if i := strings.Index(spec, "="); i > 0 {
+		name, spec = spec[:i], spec[i+1:]
+	}
+
+	entrant := Entrant{Name: name, Spec: spec}
+
+	switch {
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		player := referee.HTTPPlayer{URL: spec}
+		entrant.New = func(seed int64) (referee.Player, error) {
+			return player, nil
+		}
+	case spec == HandlerEntrant:
+		entrant.Local = true
+		player := referee.HandlerPlayer{Handler: http.HandlerFunc(controllers.GameHandler)}
+		entrant.New = func(seed int64) (referee.Player, error) {
+			return player, nil
+		}
+	case spec == RandomEntrant:
+		entrant.New = func(seed int64) (referee.Player, error) {
+			return simulator.RandomPlayer(rand.New(rand.NewSource(seed))), nil
+		}
+	case strings.HasPrefix(spec, ProfilePrefix):
+		bot := profiles[strings.TrimPrefix(spec, ProfilePrefix)]
+		if bot == nil {
+			return entrant, fmt.Errorf("no profile of %q", strings.TrimPrefix(spec, ProfilePrefix))
+		}
+
+		entrant.New = func(seed int64) (referee.Player, error) {
+			return simulator.ProfilePlayer(bot, rand.New(rand.NewSource(seed))), nil
+		}
+	default:
+		if _, err := simulator.StrategyPlayer(spec); err != nil {
+			return entrant, err
+		}
+
+		entrant.Local = true
+		strategy := spec
+		entrant.New = func(seed int64) (referee.Player, error) {
+			return simulator.StrategyPlayer(strategy)
+		}
+	}
+
+	return entrant, nil
+}
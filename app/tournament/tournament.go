@@ -0,0 +1,365 @@
+package tournament
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+	"github.com/josecleiton/domino/app/significance"
+)
+
+// Entrant is a bot of the tournament. New is called once per game, so
+// in-process strategies don't share sessions between parallel games, with a
+// seed derived from the seed of the tournament and the game.
+type Entrant struct {
+	Name string
+	// Spec is what ParseEntrant read
+	Spec string
+	// Local entrants run the code of this build
+	Local bool
+	New   func(seed int64) (referee.Player, error)
+}
+
+// Tournament is championship.rb in Go: every round, each pair of entrants
+// plays Games games, the teams swapping seats every other game
+type Tournament struct {
+	Entrants []Entrant
+	Rounds   int
+	// Games per pair each round, x1.rb plays 5
+	Games int
+	// Seed of the deals, the n-th game of every pair has the same deal
+	Seed int64
+	// Parallel games, zero uses every CPU
+	Parallel int
+	// Timeout per play, zero disables it
+	Timeout time.Duration
+	// LogDir receives the narration of every game, named and formatted like
+	// the logs of championship.rb so app/gamelog parses them
+	LogDir string
+	// Progress is called after every game, it may be nil
+	Progress func(done, total int)
+	// SPRT, when set, stops the pairings it decides before the last round
+	SPRT *significance.SPRT
+	// Rules of every game, a game is a match of many hands with a target
+	Rules models.Ruleset
+}
+
+// Pairing is the score of two entrants against each other, A before B in
+// the order of the entrants
+type Pairing struct {
+	A, B         string
+	WinsA, WinsB int
+	Domino       int
+	Closed       int
+	Disqualified int
+	// Errors are the disqualifications and the failures to start a player
+	Errors []error
+	// Stopped by the SPRT before the last round
+	Stopped bool
+}
+
+func (p Pairing) Games() int {
+	return p.WinsA + p.WinsB
+}
+
+// Score of A against B
+func (p Pairing) Score() significance.Score {
+	return significance.Score{Wins: p.WinsA, Losses: p.WinsB}
+}
+
+type Standing struct {
+	Name   string
+	Games  int
+	Wins   int
+	Losses int
+}
+
+func (s Standing) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+
+	return float64(s.Wins) / float64(s.Games)
+}
+
+type Results struct {
+	Pairings []Pairing
+	// Standings by wins, then fewer games, then name
+	Standings []Standing
+}
+
+// Pairing of a and b, with a's wins first
+func (r Results) Pairing(a, b string) (Pairing, bool) {
+	for _, p := range r.Pairings {
+		switch {
+		case p.A == a && p.B == b:
+			return p, true
+		case p.A == b && p.B == a:
+			return Pairing{
+				A:            a,
+				B:            b,
+				WinsA:        p.WinsB,
+				WinsB:        p.WinsA,
+				Domino:       p.Domino,
+				Closed:       p.Closed,
+				Disqualified: p.Disqualified,
+				Errors:       p.Errors,
+				Stopped:      p.Stopped,
+			}, true
+		}
+	}
+
+	return Pairing{}, false
+}
+
+type job struct {
+	Round   int
+	Pairing int
+	Game    int
+}
+
+// Run plays every game and sums them up
+func (t Tournament) Run() (Results, error) {
+	if len(t.Entrants) < 2 {
+		return Results{}, fmt.Errorf("a tournament needs at least 2 entrants, got %d", len(t.Entrants))
+	}
+
+	if t.LogDir != "" {
+		if err := os.MkdirAll(t.LogDir, 0755); err != nil {
+			return Results{}, err
+		}
+	}
+
+	pairings := make([]Pairing, 0, len(t.Entrants)*(len(t.Entrants)-1)/2)
+	pairs := make([][2]int, 0, cap(pairings))
+	for i := range t.Entrants {
+		for j := i + 1; j < len(t.Entrants); j++ {
+			pairings = append(pairings, Pairing{A: t.Entrants[i].Name, B: t.Entrants[j].Name})
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+
+	rounds, games := max(1, t.Rounds), max(1, t.Games)
+	total := rounds * len(pairs) * games
+
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+		// pending are the games of the round being played
+		pending sync.WaitGroup
+		done    int
+		err     error
+	)
+
+	decided := func(pairing int) bool {
+		if t.SPRT == nil {
+			return false
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if t.SPRT.Verdict(pairings[pairing].Score()) == significance.Undecided {
+			return false
+		}
+
+		pairings[pairing].Stopped = true
+
+		return true
+	}
+
+	// the verdicts are taken between rounds, on every game played so far,
+	// so a pairing stops at the same game whatever the workers' timing
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		for round := 0; round < rounds; round++ {
+			for pairing := range pairs {
+				if decided(pairing) {
+					continue
+				}
+
+				for game := 0; game < games; game++ {
+					pending.Add(1)
+					jobs <- job{Round: round, Pairing: pairing, Game: game}
+				}
+			}
+
+			pending.Wait()
+		}
+	}()
+
+	parallel := t.Parallel
+	if parallel <= 0 {
+		parallel = runtime.NumCPU()
+	}
+
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				pair := pairs[j.Pairing]
+				result, playErr := t.play(j, t.Entrants[pair[0]], t.Entrants[pair[1]])
+
+				mutex.Lock()
+				if playErr != nil {
+					pairings[j.Pairing].Errors = append(pairings[j.Pairing].Errors, playErr)
+					if err == nil {
+						err = playErr
+					}
+				} else {
+					pairings[j.Pairing].add(result)
+				}
+
+				done++
+				if t.Progress != nil {
+					t.Progress(done, total)
+				}
+				mutex.Unlock()
+
+				pending.Done()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return Results{Pairings: pairings, Standings: standings(t.Entrants, pairings)}, err
+}
+
+// gameResult is a game from the point of view of the pairing
+type gameResult struct {
+	AWon    bool
+	Outcome referee.Outcome
+	Err     error
+}
+
+func (p *Pairing) add(result gameResult) {
+	if result.AWon {
+		p.WinsA++
+	} else {
+		p.WinsB++
+	}
+
+	switch result.Outcome {
+	case referee.OutcomeDomino:
+		p.Domino++
+	case referee.OutcomeClosed:
+		p.Closed++
+	case referee.OutcomeDisqualified:
+		p.Disqualified++
+		p.Errors = append(p.Errors, result.Err)
+	}
+}
+
+func (t Tournament) play(j job, a, b Entrant) (gameResult, error) {
+	playerA, err := a.New(t.playerSeed(j, 0))
+	if err != nil {
+		return gameResult{}, fmt.Errorf("%s: %w", a.Name, err)
+	}
+
+	playerB, err := b.New(t.playerSeed(j, 1))
+	if err != nil {
+		return gameResult{}, fmt.Errorf("%s: %w", b.Name, err)
+	}
+
+	// a sits on the odd seats on the even games
+	n := int64(j.Round)*int64(max(1, t.Games)) + int64(j.Game)
+	offset := int(n % 2)
+	players := [2]referee.Player{playerA, playerB}
+	names := [2]string{a.Name, b.Name}
+
+	var log bytes.Buffer
+	match := referee.Match{Timeout: t.Timeout, Rules: t.Rules}
+	for seat := range match.Players {
+		match.Players[seat] = players[(seat+offset)%2]
+	}
+
+	if t.LogDir != "" {
+		match.Log = &log
+		for seat := range match.Players {
+			fmt.Fprintf(&log, "Iniciando container do jogador %d... bots/%s\n", seat+models.DominoMinPlayer, names[(seat+offset)%2])
+		}
+		fmt.Fprintf(&log, "Iniciando partida...\n\n")
+	}
+
+	rng := rand.New(rand.NewSource(t.Seed + n))
+	series := match.RunSeries(func() referee.Hands {
+		return referee.DealSet(rng, t.Rules.Set)
+	})
+	result := series.Last()
+
+	// the team of the odd seats is bot1
+	winner := (int(series.Winner) - 1 + offset) % 2
+
+	if t.LogDir != "" {
+		fmt.Fprintf(&log, "Vencedor: bot%d.\n", int(series.Winner))
+
+		name := fmt.Sprintf(
+			"round %03d -- jogo %03d -- %s vs %s.txt",
+			j.Round+1,
+			j.Game+1,
+			names[offset],
+			names[1-offset],
+		)
+		if err := os.WriteFile(filepath.Join(t.LogDir, name), log.Bytes(), 0644); err != nil {
+			return gameResult{}, err
+		}
+	}
+
+	return gameResult{AWon: winner == 0, Outcome: result.Outcome, Err: result.Err}, nil
+}
+
+// playerSeed of side 0, A, or 1, B, in the game j, the same whatever the
+// order the games run in
+func (t Tournament) playerSeed(j job, side int) int64 {
+	seed := t.Seed
+	for _, n := range []int{j.Round, j.Pairing, j.Game, side} {
+		seed = seed*1_000_003 + int64(n)
+	}
+
+	return seed
+}
+
+func standings(entrants []Entrant, pairings []Pairing) []Standing {
+	byName := make(map[string]*Standing, len(entrants))
+	standings := make([]Standing, len(entrants))
+	for i, entrant := range entrants {
+		standings[i].Name = entrant.Name
+		byName[entrant.Name] = &standings[i]
+	}
+
+	for _, p := range pairings {
+		a, b := byName[p.A], byName[p.B]
+		a.Games += p.Games()
+		b.Games += p.Games()
+		a.Wins += p.WinsA
+		a.Losses += p.WinsB
+		b.Wins += p.WinsB
+		b.Losses += p.WinsA
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Wins != standings[j].Wins {
+			return standings[i].Wins > standings[j].Wins
+		}
+
+		if standings[i].Games != standings[j].Games {
+			return standings[i].Games < standings[j].Games
+		}
+
+		return standings[i].Name < standings[j].Name
+	})
+
+	return standings
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/josecleiton/domino/app/gamelog"
+	"github.com/josecleiton/domino/app/profile"
+)
+
+func main() {
+	output := flag.String("o", "profiles.json", "file the profiles are written to")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [log dir...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = gamelog.Dirs
+	}
+
+	var games []*gamelog.Game
+	for _, dir := range dirs {
+		parsed, err := gamelog.ParseDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		games = append(games, parsed...)
+	}
+
+	profiles := profile.Build(games)
+	if err := profiles.Save(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	bots := make([]string, 0, len(profiles))
+	for bot := range profiles {
+		bots = append(bots, bot)
+	}
+	sort.Strings(bots)
+
+	// bias is how many times more often than chance each feature was picked
+	fmt.Printf(
+		"%-16s %6s %9s %8s %8s %8s %8s %8s\n",
+		"bot", "games", "decisions", "heaviest", "opening", "doubles", "block", "pressure",
+	)
+	for _, bot := range bots {
+		p := profiles[bot]
+		fmt.Printf(
+			"%-16s %6d %9d %8.2f %8.2f %8.2f %8.2f %8.2f\n",
+			bot,
+			p.Games,
+			p.Decisions,
+			p.Play.Heaviest.Bias(),
+			p.Opening.Heaviest.Bias(),
+			p.EarlyDoubles.Bias(),
+			p.BlockPartner.Bias(),
+			p.PassPressure.Bias(),
+		)
+	}
+}
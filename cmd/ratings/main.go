@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/josecleiton/domino/app/rating"
+)
+
+func main() {
+	ratings := flag.String("ratings", "ratings.json", "rating ledger")
+	name := flag.String("name", "", "only the entrants with this name")
+	history := flag.Bool("history", false, "oldest entrants first instead of the strongest")
+	flag.Parse()
+
+	ledger, err := rating.Load(*ratings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	entries := ledger.Sorted()
+	if *history {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Created.Before(entries[j].Created)
+		})
+	}
+
+	shown := entries[:0]
+	for _, entry := range entries {
+		if *name == "" || strings.EqualFold(entry.Name, *name) {
+			shown = append(shown, entry)
+		}
+	}
+
+	rating.WriteTable(os.Stdout, shown)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/josecleiton/domino/app/controllers"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+func main() {
+	bot1 := flag.String("bot1", "", "URL of bot 1, empty runs this bot in-process")
+	bot2 := flag.String("bot2", "", "URL of bot 2, empty runs this bot in-process")
+	games := flag.Int("games", 1, "number of games")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed used to shuffle the bones")
+	timeout := flag.Duration("timeout", referee.DefaultTimeout, "time limit per play")
+	quiet := flag.Bool("quiet", false, "do not narrate the games")
+	rules := flag.String("rules", models.ClassicRules.String(), "ruleset of the games, classic, brazilian, points or a list like target=6,bonuses,set=double-nine")
+	flag.Parse()
+
+	ruleset, err := models.ParseRuleset(*rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	bots := []referee.Player{player(*bot1), player(*bot2)}
+
+	var log io.Writer = os.Stdout
+	if *quiet {
+		log = nil
+	}
+
+	results := [2]int{}
+	for i := 0; i < max(1, *games); i++ {
+		// bot1 sits on the odd seats half of the time, like run_domino.js
+		offset := rng.Intn(2)
+
+		match := referee.Match{Timeout: *timeout, Log: log, Rules: ruleset}
+		for seat := range match.Players {
+			match.Players[seat] = bots[(seat+offset)%2]
+		}
+
+		series := match.RunSeries(func() referee.Hands {
+			return referee.DealSet(rng, ruleset.Set)
+		})
+
+		winner := (int(series.Winner) - 1 + offset) % 2
+		results[winner]++
+
+		fmt.Printf("Partida %d: Vencedor: bot%d.\n", i+1, winner+1)
+		if ruleset.Target > 0 {
+			fmt.Printf(
+				"  %d mãos, bot1 %d x %d bot2 pontos\n",
+				len(series.Hands),
+				series.Points[offset],
+				series.Points[1-offset],
+			)
+		}
+		fmt.Printf("  Resultado parcial: bot1 %d x %d bot2\n\n", results[0], results[1])
+	}
+
+	fmt.Printf("Resultado final após %d partidas: bot1 %d x %d bot2\n", max(1, *games), results[0], results[1])
+}
+
+func player(url string) referee.Player {
+	if url == "" {
+		return referee.HandlerPlayer{Handler: http.HandlerFunc(controllers.GameHandler)}
+	}
+
+	return referee.HTTPPlayer{URL: url}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/gamelog"
+	"github.com/josecleiton/domino/app/models"
+)
+
+func main() {
+	bot := flag.String("bot", "josecleiton", "bot whose seats are replayed, empty replays every seat")
+	strategy := flag.String(
+		"strategy",
+		game.DefaultStrategy,
+		"strategy of game.Play, one of: "+strings.Join(game.Strategies(), ", "),
+	)
+	timeout := flag.Duration("timeout", game.PlayTimeout, "think time of each play")
+	verbose := flag.Bool("verbose", false, "keep the game package logs")
+	guessTree := flag.Bool("tree", false, "generate the guess tree in background")
+	export := flag.String("export", "", "write the parsed games as JSON Lines game records to this file and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [log file or dir...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if !game.HasStrategy(*strategy) {
+		fmt.Fprintf(os.Stderr, "Error: unknown strategy %q\n", *strategy)
+		os.Exit(1)
+	}
+
+	game.DefaultStrategy = *strategy
+	game.PlayTimeout = *timeout
+	game.GuessTree = *guessTree
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{gamelog.Dirs[0]}
+	}
+
+	if *export != "" {
+		if err := exportRecords(*export, paths); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	decisions, diffs := 0, 0
+	for _, path := range paths {
+		games, err := parse(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		for _, g := range games {
+			d, n, err := replay(g, *bot)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				continue
+			}
+
+			decisions += d
+			diffs += n
+		}
+	}
+
+	agreement := 0.0
+	if decisions > 0 {
+		agreement = float64(decisions-diffs) / float64(decisions)
+	}
+
+	fmt.Printf("decisions: %d\n", decisions)
+	fmt.Printf("diffs:     %d\n", diffs)
+	fmt.Printf("agreement: %.4f\n", agreement)
+}
+
+func parse(path string) ([]*gamelog.Game, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return gamelog.ParseDir(path)
+	}
+
+	return gamelog.ParseFile(path)
+}
+
+func exportRecords(name string, paths []string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, path := range paths {
+		games, err := parse(path)
+		if err != nil {
+			return err
+		}
+
+		for _, g := range games {
+			if err := models.WriteGameRecord(f, g.Record()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return f.Close()
+}
+
+// replay asks game.Play every decision of the seats of bot, printing the
+// ones it would take differently
+func replay(g *gamelog.Game, bot string) (int, int, error) {
+	decisions, err := g.Decisions()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count, diffs := 0, 0
+	for _, decision := range decisions {
+		player := decision.State.PlayerPosition
+		if bot != "" && g.Bots[player-models.DominoMinPlayer] != bot {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), game.PlayTimeout)
+		play := game.Play(ctx, decision.State)
+		cancel()
+
+		count++
+		if same(decision.State, decision.Play, play) {
+			continue
+		}
+
+		diffs++
+		fmt.Printf(
+			"%s: turn %d, player %d (%s): logged %s, chose %s\n",
+			filepath.Base(g.Source),
+			decision.Turn,
+			player,
+			g.Bots[player-models.DominoMinPlayer],
+			describe(decision.Play),
+			describe(play),
+		)
+	}
+
+	return count, diffs, nil
+}
+
+// same ignores the edge when both edges have the same pip
+func same(state *models.DominoGameState, logged, chosen models.DominoPlayWithPass) bool {
+	if logged.Pass() || chosen.Pass() {
+		return logged.Pass() == chosen.Pass()
+	}
+
+	if !logged.Bone.Domino.Equals(chosen.Bone.Domino) {
+		return false
+	}
+
+	if logged.Bone.Edge == chosen.Bone.Edge {
+		return true
+	}
+
+	return len(state.Table) > 0 && state.Table[0].L == state.Table[len(state.Table)-1].R
+}
+
+func describe(play models.DominoPlayWithPass) string {
+	if play.Pass() {
+		return "pass"
+	}
+
+	return fmt.Sprintf("[%s] %s", models.DominoToString(play.Bone.Domino), models.SideFromEdge(play.Bone.Edge))
+}
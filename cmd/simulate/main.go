@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+	"github.com/josecleiton/domino/app/rating"
+	"github.com/josecleiton/domino/app/referee"
+	"github.com/josecleiton/domino/app/significance"
+	"github.com/josecleiton/domino/app/simulator"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "number of deals")
+	seed := flag.Int64("seed", 1, "seed used to shuffle the bones")
+	strategy := flag.String("strategy", game.DefaultStrategy, "evaluated strategy")
+	opponent := flag.String(
+		"opponent",
+		game.GlueStrategy,
+		"opponent strategy or random, one of: "+strings.Join(game.Strategies(), ", "),
+	)
+	verbose := flag.Bool("verbose", false, "keep the game package logs")
+	guessTree := flag.Bool("tree", false, "generate the guess tree in background")
+	timeout := flag.Duration("timeout", game.PlayTimeout, "think time of each play")
+	profiles := flag.String("profiles", "profiles.json", "opponent profiles built by cmd/profile")
+	bot := flag.String("bot", "", "the opponent imitates this bot of -profiles instead of -opponent")
+	know := flag.Bool("know", false, "tell the evaluated strategy which bot it faces")
+	duplicate := flag.Bool("duplicate", false, "play every deal from both sides of the table")
+	seatings := flag.Bool("seatings", false, "with -duplicate, also deal the hands in every order around the table")
+	ratings := flag.String("ratings", "", "rating ledger updated with the results")
+	rules := flag.String("rules", models.ClassicRules.String(), "ruleset of the games, classic, brazilian, points or a list like target=6,bonuses,set=double-nine")
+	flag.Parse()
+
+	ruleset, err := models.ParseRuleset(*rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	game.GuessTree = *guessTree
+	game.PlayTimeout = *timeout
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	var opponentProfile *profile.Profile
+	if *bot != "" {
+		loaded, err := profile.Load(*profiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		if opponentProfile = loaded[*bot]; opponentProfile == nil {
+			fmt.Fprintf(os.Stderr, "Error: no profile of %q in %s\n", *bot, *profiles)
+			os.Exit(1)
+		}
+	}
+
+	var known *profile.Profile
+	if *know {
+		known = opponentProfile
+	}
+
+	player, err := simulator.ProfiledStrategyPlayer(*strategy, known)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	var against referee.Player
+	if opponentProfile != nil {
+		against = simulator.ProfilePlayer(opponentProfile, rand.New(rand.NewSource(*seed)))
+	} else if *opponent == "random" {
+		against = simulator.RandomPlayer(rand.New(rand.NewSource(*seed)))
+	} else if against, err = simulator.StrategyPlayer(*opponent); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	start := time.Now()
+
+	var stats simulator.Stats
+	if *duplicate {
+		dup := simulator.RunDuplicate(*games, *seed, ruleset, player, against, *seatings)
+		stats = dup.Stats
+
+		fmt.Printf("deals:        %d (%d swept by the strategy, %d by the opponent)\n", dup.Deals, dup.Sweeps, dup.Swept)
+		fmt.Printf("std error:    %.4f (%.4f if every game had its own deal)\n", dup.StdErr(), stats.StdErr())
+	} else {
+		stats = simulator.RunRules(*games, *seed, ruleset, player, against)
+	}
+
+	fmt.Printf("games:        %d (%s)\n", stats.Games, time.Since(start).Round(time.Millisecond))
+	fmt.Printf("win rate:     %.4f (%d x %d)\n", stats.WinRate(), stats.Wins, stats.Losses)
+	fmt.Printf("domino:       %d\n", stats.Domino)
+	fmt.Printf("closed:       %d\n", stats.Closed)
+	fmt.Printf("disqualified: %d\n", stats.Disqualified)
+	fmt.Printf(
+		"significance: %s\n",
+		significance.Report(significance.Score{Wins: stats.Wins, Losses: stats.Losses}, significance.DefaultSPRT),
+	)
+
+	for _, err := range stats.Errors {
+		fmt.Printf("  %s\n", err)
+	}
+	if *ratings != "" {
+		against := *opponent
+		if *bot != "" {
+			against = "profile:" + *bot
+		}
+
+		params := fmt.Sprintf("timeout=%s", *timeout)
+		if ruleset != models.ClassicRules {
+			params += fmt.Sprintf(",rules=%s", ruleset)
+		}
+
+		if err := updateRatings(*ratings, *strategy, against, params, stats); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// updateRatings records the games in the ledger, the strategies under the
+// commit of this build
+func updateRatings(name, strategy, opponent, params string, stats simulator.Stats) error {
+	ledger, err := rating.Load(name)
+	if err != nil {
+		return err
+	}
+
+	revision := rating.Revision()
+	identity := func(name string) rating.Identity {
+		if game.HasStrategy(name) {
+			return rating.Identity{Name: name, Commit: revision, Params: params}
+		}
+
+		return rating.Identity{Name: name}
+	}
+
+	player, against := ledger.Entry(identity(strategy)), ledger.Entry(identity(opponent))
+	ledger.Record(player, against, stats.Wins, stats.Losses)
+
+	if err := ledger.Save(name); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	rating.WriteTable(os.Stdout, []*rating.Entry{player, against})
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+	"github.com/josecleiton/domino/app/rating"
+	"github.com/josecleiton/domino/app/significance"
+	"github.com/josecleiton/domino/app/tournament"
+)
+
+func main() {
+	rounds := flag.Int("rounds", 10, "rounds, every pair meets in each one")
+	games := flag.Int("games", 1, "games of every pair in each round")
+	seed := flag.Int64("seed", 1, "seed used to shuffle the bones")
+	parallel := flag.Int("parallel", 0, "games played at once, 0 uses every CPU")
+	timeout := flag.Duration("timeout", game.PlayTimeout, "think time of the in-process strategies")
+	limit := flag.Duration("limit", 0, "time limit per play, 0 disables it")
+	profiles := flag.String("profiles", "", "opponent profiles built by cmd/profile, for profile:<bot> entrants")
+	logDir := flag.String("logs", "", "directory for the narration of every game")
+	verbose := flag.Bool("verbose", false, "keep the game package logs")
+	ratings := flag.String("ratings", "", "rating ledger updated with the results")
+	extra := flag.String("params", "", "parameters of the in-process entrants kept in the ledger, after the timeout")
+	sprt := flag.Bool("sprt", false, "stop each pair once the SPRT decides it, -rounds is then the most rounds")
+	rules := flag.String("rules", models.ClassicRules.String(), "ruleset of the games, classic, brazilian, points or a list like target=6,bonuses,set=double-nine")
+	p1 := flag.Float64("p1", significance.DefaultSPRT.P1, "win rate the SPRT tells from an even pair")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] entrant entrant [entrant...]\n", os.Args[0])
+		fmt.Fprintf(
+			flag.CommandLine.Output(),
+			"An entrant is a strategy (%s), a bot URL, %s, %s or %s<bot>, optionally named as name=entrant.\n",
+			strings.Join(game.Strategies(), ", "),
+			tournament.HandlerEntrant,
+			tournament.RandomEntrant,
+			tournament.ProfilePrefix,
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	game.PlayTimeout = *timeout
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	ruleset, err := models.ParseRuleset(*rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	var loaded profile.Profiles
+	if *profiles != "" {
+		if loaded, err = profile.Load(*profiles); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	t := tournament.Tournament{
+		Rounds:   *rounds,
+		Games:    *games,
+		Seed:     *seed,
+		Parallel: *parallel,
+		Timeout:  *limit,
+		LogDir:   *logDir,
+		Rules:    ruleset,
+		Progress: func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d games", done, total)
+		},
+	}
+
+	test := significance.DefaultSPRT
+	test.P1 = *p1
+	if *sprt {
+		t.SPRT = &test
+	}
+
+	for _, spec := range flag.Args() {
+		entrant, err := tournament.ParseEntrant(spec, loaded)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		t.Entrants = append(t.Entrants, entrant)
+	}
+
+	start := time.Now()
+	results, err := t.Run()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+	}
+
+	upTo := ""
+	if *sprt {
+		upTo = "up to "
+	}
+	fmt.Printf("%s%d rounds in %s\n\n", upTo, max(1, *rounds), time.Since(start).Round(time.Millisecond))
+	printPairings(results, test)
+	fmt.Println()
+	printStandings(results)
+
+	if *ratings != "" {
+		fmt.Println()
+
+		params := fmt.Sprintf("timeout=%s", *timeout)
+		if ruleset != models.ClassicRules {
+			params += fmt.Sprintf(",rules=%s", ruleset)
+		}
+		if *extra != "" {
+			params += "," + *extra
+		}
+
+		if ratingErr := updateRatings(*ratings, t.Entrants, results, params); ratingErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ratingErr)
+			os.Exit(1)
+		}
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// updateRatings records every pairing in the ledger, the in-process
+// entrants under the commit of this build
+func updateRatings(name string, entrants []tournament.Entrant, results tournament.Results, params string) error {
+	ledger, err := rating.Load(name)
+	if err != nil {
+		return err
+	}
+
+	revision := rating.Revision()
+	entries := make(map[string]*rating.Entry, len(entrants))
+	for _, entrant := range entrants {
+		id := rating.Identity{Name: entrant.Name}
+		if entrant.Local {
+			id.Commit, id.Params = revision, params
+		}
+
+		entries[entrant.Name] = ledger.Entry(id)
+	}
+
+	for _, p := range results.Pairings {
+		ledger.Record(entries[p.A], entries[p.B], p.WinsA, p.WinsB)
+	}
+
+	if err := ledger.Save(name); err != nil {
+		return err
+	}
+
+	rated := make([]*rating.Entry, 0, len(entries))
+	for _, entry := range ledger.Sorted() {
+		if entries[entry.Name] == entry {
+			rated = append(rated, entry)
+		}
+	}
+	rating.WriteTable(os.Stdout, rated)
+
+	return nil
+}
+
+func printPairings(results tournament.Results, test significance.SPRT) {
+	width := 0
+	for _, p := range results.Pairings {
+		width = max(width, len(p.A)+len(p.B)+4)
+	}
+
+	for _, p := range results.Pairings {
+		fmt.Printf(
+			"%-*s %4d x %-4d domino %-4d closed %-4d disqualified %d\n",
+			width,
+			p.A+" vs "+p.B,
+			p.WinsA,
+			p.WinsB,
+			p.Domino,
+			p.Closed,
+			p.Disqualified,
+		)
+
+		report := significance.Report(p.Score(), test)
+		if p.Stopped {
+			report += ", stopped"
+		}
+		fmt.Printf("  %s\n", report)
+
+		for _, err := range p.Errors {
+			fmt.Printf("  %s\n", err)
+		}
+	}
+}
+
+// printStandings prints the table of championship.rb: the wins of each row
+// against each column, then the totals
+func printStandings(results tournament.Results) {
+	width := 0
+	for _, s := range results.Standings {
+		width = max(width, len(s.Name))
+	}
+
+	fmt.Printf("%*s", width+4, "")
+	for i := range results.Standings {
+		fmt.Printf(" %7s", fmt.Sprintf("%02d", i+1))
+	}
+	fmt.Printf(" %9s %7s\n", "wins", "rate")
+
+	for i, row := range results.Standings {
+		fmt.Printf("%02d. %-*s", i+1, width, row.Name)
+		for _, column := range results.Standings {
+			if column.Name == row.Name {
+				fmt.Printf(" %7s", "")
+				continue
+			}
+
+			p, _ := results.Pairing(row.Name, column.Name)
+			fmt.Printf(" %7s", fmt.Sprintf("%d-%d", p.WinsA, p.WinsB))
+		}
+		fmt.Printf(" %9s %7.4f\n", fmt.Sprintf("%d/%d", row.Wins, row.Games), row.WinRate())
+	}
+}
@@ -3,3 +3,5 @@ module github.com/josecleiton/domino
 go 1.21.3
 
 require gonum.org/v1/gonum v0.14.0 // direct
+
+require golang.org/x/exp v0.0.0-20230321023759-10a507213a29 // indirect
@@ -5,16 +5,74 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/josecleiton/domino/app/controllers"
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/profile"
 )
 
 func main() {
+	if strategy := os.Getenv("DOMINO_STRATEGY"); strategy != "" {
+		if !game.HasStrategy(strategy) {
+			fmt.Fprintf(os.Stderr, "Error: unknown strategy %q\n", strategy)
+			os.Exit(1)
+		}
+
+		game.DefaultStrategy = strategy
+	}
+
+	if timeout := os.Getenv("DOMINO_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid timeout %q\n", timeout)
+			os.Exit(1)
+		}
+
+		game.PlayTimeout = duration
+	}
+
+	if profiles := os.Getenv("DOMINO_PROFILES"); profiles != "" {
+		loaded, err := profile.Load(profiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		controllers.Profiles = loaded
+	}
+
+	if dump := os.Getenv("DOMINO_DUMP"); dump != "" {
+		f, err := os.OpenFile(dump, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		controllers.StateDump = f
+	}
+
+	if os.Getenv("DOMINO_STRICT") != "" {
+		controllers.Strict = true
+	}
+
 	http.HandleFunc("/", controllers.GameHandler)
+	// the beliefs publish the inferred hands of the opponents, served only
+	// when debugging
+	if os.Getenv("DOMINO_DEBUG") != "" {
+		http.HandleFunc("/debug/beliefs", controllers.BeliefsHandler)
+	}
+	http.HandleFunc("/explain", controllers.ExplainHandler)
 
 	port := ":8000"
 
-	log.Printf("Server started at http://localhost%s\n", port)
+	log.Printf(
+		"Server started at http://localhost%s with %s strategy and %s per play\n",
+		port,
+		game.DefaultStrategy,
+		game.PlayTimeout,
+	)
 
 	err := http.ListenAndServe(port, nil)
 
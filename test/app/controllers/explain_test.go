@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josecleiton/domino/app/controllers"
+)
+
+type explainResponse struct {
+	Rule  string `json:"rule"`
+	Moves []struct {
+		Chosen  bool     `json:"chosen"`
+		Reasons []string `json:"reasons"`
+	} `json:"jogadas"`
+}
+
+func TestExplain(t *testing.T) {
+	body := `{
+		"jogador": 2,
+		"mao": ["6-5", "6-1", "2-3", "0-0", "4-4", "1-2", "3-5"],
+		"mesa": ["6-6"],
+		"jogadas": [{"jogador": 1, "pedra": "6-6"}]
+	}`
+
+	w := httptest.NewRecorder()
+	controllers.ExplainHandler(w, httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Wrong status %d %s", w.Code, w.Body)
+	}
+
+	var resp explainResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// 6-5 and 6-1 on both edges
+	chosen := 0
+	for _, move := range resp.Moves {
+		if move.Chosen {
+			chosen++
+		}
+
+		if len(move.Reasons) == 0 {
+			t.Errorf("Move without reasons %v", resp)
+		}
+	}
+
+	if resp.Rule == "" || len(resp.Moves) != 4 || chosen != 1 {
+		t.Errorf("Wrong explanation %s", w.Body)
+	}
+}
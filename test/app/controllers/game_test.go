@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josecleiton/domino/app/controllers"
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/models"
+)
+
+type hangStrategy struct{}
+
+func (hangStrategy) Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	time.Sleep(10 * time.Second)
+	return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+}
+
+func init() {
+	game.RegisterStrategy("test-hang", func(*game.Session) game.Strategy { return hangStrategy{} })
+}
+
+// the strategy cut off by the guard still holds the session, the next
+// request of the match doesn't wait for it past its own deadline
+func TestGameHandlerHungSession(t *testing.T) {
+	body := `{
+		"jogador": 2,
+		"mao": ["6-5", "6-1", "2-3", "0-0", "4-4", "1-2", "3-5"],
+		"mesa": ["6-6"],
+		"jogadas": [{"jogador": 1, "pedra": "6-6"}]
+	}`
+
+	timeout := game.PlayTimeout
+	game.PlayTimeout = 100 * time.Millisecond
+	defer func() { game.PlayTimeout = timeout }()
+
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/?strategy=test-hang", strings.NewReader(body))
+		r.Header.Set(controllers.MatchIDHeader, "hung-session")
+		r.Header.Set(controllers.OpponentHeader, "bot")
+
+		start := time.Now()
+		w := httptest.NewRecorder()
+		controllers.GameHandler(w, r)
+		if elapsed := time.Since(start); elapsed > game.PlayTimeout+game.GuardGrace+100*time.Millisecond {
+			t.Errorf("Request %d waited %s", i, elapsed)
+		}
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Wrong status %d %s", w.Code, w.Body)
+		}
+	}
+}
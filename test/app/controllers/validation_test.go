@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josecleiton/domino/app/controllers"
+)
+
+type errorResponse struct {
+	Errors controllers.ValidationErrors `json:"errors"`
+}
+
+// player 3 passed on 6 and plays 6-0 later, 4-0 is played twice, 0-2 has
+// no side, the mesa has 4-5 for 4-0 and the hand has an invalid bone, a
+// repeated one and one on the table
+const invalidState = `{
+	"jogador": 3,
+	"mao": ["3-3", "abc", "2-3", "2-3", "6-1", "5-5"],
+	"mesa": ["6-0", "6-1", "1-4", "4-5"],
+	"jogadas": [
+		{"jogador": 1, "pedra": "1-4"},
+		{"jogador": 2, "pedra": "6-1", "lado": "esquerda"},
+		{"jogador": 4, "pedra": "4-0", "lado": "direita"},
+		{"jogador": 1, "pedra": "4-0", "lado": "direita"},
+		{"jogador": 3, "pedra": "6-0", "lado": "esquerda"},
+		{"jogador": 4, "pedra": "0-2", "lado": "cima"}
+	]
+}`
+
+// post asks for the problems of the request, postLenient for the play
+func post(body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set(controllers.StrictHeader, "1")
+
+	w := httptest.NewRecorder()
+	controllers.GameHandler(w, r)
+
+	return w
+}
+
+func postLenient(body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	controllers.GameHandler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	return w
+}
+
+func TestValidation(t *testing.T) {
+	w := post(invalidState)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Wrong status %d", w.Code)
+	}
+
+	var resp errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]controllers.ValidationCode{
+		"jogadas[3].pedra":   controllers.CodeDuplicateBone,
+		"jogadas[5].lado":    controllers.CodeInvalidSide,
+		"jogadas[4].jogador": controllers.CodeOutOfTurn,
+		"mesa[3]":            controllers.CodeTableMismatch,
+		"mao[1]":             controllers.CodeInvalidBone,
+		"mao[3]":             controllers.CodeDuplicateBone,
+		"mao[4]":             controllers.CodeHandOnTable,
+		"mao":                controllers.CodeHandSize,
+	}
+
+	got := make(map[string]controllers.ValidationCode, len(resp.Errors))
+	for _, err := range resp.Errors {
+		got[err.Field] = err.Code
+	}
+
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("Wrong code of %s %q, errors %v", field, got[field], resp.Errors)
+		}
+	}
+
+	// the mesa may have a bone turned
+	if len(got) != len(want) {
+		t.Errorf("Wrong errors %v", resp.Errors)
+	}
+}
+
+func TestLenient(t *testing.T) {
+	if w := postLenient(invalidState); w.Code != http.StatusOK {
+		t.Errorf("Wrong status %d %s", w.Code, w.Body)
+	}
+
+	if w := postLenient(`{"jogador": 5, "mao": ["6-6"]}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Wrong status %d", w.Code)
+	}
+
+	controllers.Strict = true
+	defer func() { controllers.Strict = false }()
+
+	if w := postLenient(invalidState); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Wrong status %d", w.Code)
+	}
+}
+
+// the seats skipped between two plays passed, player 1 couldn't pass on 6
+// holding 6-1
+func TestTurnOrder(t *testing.T) {
+	tests := []struct {
+		table string
+		plays string
+		field string
+	}{
+		// 2 plays after 4, skipping 1
+		{`"6-6", "6-5", "5-4", "4-3", "3-2"`, `{"jogador": 1, "pedra": "6-6"},
+			{"jogador": 2, "pedra": "6-5", "lado": "direita"},
+			{"jogador": 3, "pedra": "5-4", "lado": "direita"},
+			{"jogador": 4, "pedra": "4-3", "lado": "direita"},
+			{"jogador": 2, "pedra": "3-2", "lado": "direita"}`, "jogadas[4].jogador"},
+		// 2 plays twice in a row
+		{`"6-6", "6-5", "5-4"`, `{"jogador": 1, "pedra": "6-6"},
+			{"jogador": 2, "pedra": "6-5", "lado": "direita"},
+			{"jogador": 2, "pedra": "5-4", "lado": "direita"}`, "jogadas[2].jogador"},
+		// the others passed on 0 and 3, 1 holds neither
+		{`"0-6", "6-6", "6-3", "3-3"`, `{"jogador": 1, "pedra": "6-6"},
+			{"jogador": 2, "pedra": "6-3", "lado": "direita"},
+			{"jogador": 3, "pedra": "6-0", "lado": "esquerda"},
+			{"jogador": 3, "pedra": "3-3", "lado": "direita"}`, ""},
+	}
+
+	for _, test := range tests {
+		w := post(`{"jogador": 1, "mao": ["6-1", "1-1", "1-2", "2-2", "1-4", "2-4"], ` +
+			`"mesa": [` + test.table + `], "jogadas": [` + test.plays + `]}`)
+
+		var resp errorResponse
+		if w.Code == http.StatusUnprocessableEntity {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if test.field == "" {
+			if w.Code != http.StatusOK {
+				t.Errorf("Wrong status %d %v", w.Code, resp.Errors)
+			}
+
+			continue
+		}
+
+		if len(resp.Errors) != 1 || resp.Errors[0].Field != test.field || resp.Errors[0].Code != controllers.CodeOutOfTurn {
+			t.Errorf("Wrong errors %v, want %s out of turn", resp.Errors, test.field)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/models"
+)
+
+type strategyFunc func(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass
+
+func (f strategyFunc) Choose(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+	return f(ctx, state)
+}
+
+func init() {
+	register := func(name string, f strategyFunc) {
+		game.RegisterStrategy(name, func(*game.Session) game.Strategy { return f })
+	}
+
+	register("test-panic", func(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+		panic("no play found")
+	})
+	register("test-pass", func(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	})
+	register("test-slow", func(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+		time.Sleep(time.Second)
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	})
+	register("test-hang", func(ctx context.Context, state *models.DominoGameState) models.DominoPlayWithPass {
+		time.Sleep(10 * time.Second)
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}
+	})
+}
+
+func TestPlayGuarded(t *testing.T) {
+	state := loadState(t, "generate_tree.json")
+	if len(state.LegalPlays()) == 0 {
+		t.Fatal("Fixture without legal plays")
+	}
+
+	for _, name := range []string{"test-panic", "test-pass", "test-slow", game.DefaultStrategy} {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		play := game.NewSession().PlayGuarded(ctx, name, state)
+		cancel()
+
+		if play.Pass() {
+			t.Errorf("Pass of %s with legal plays", name)
+		} else if err := state.CheckPlay(play); err != nil {
+			t.Errorf("Illegal play of %s: %s", name, err)
+		}
+	}
+}
+
+// without a deadline the guard waits PlayTimeout for the strategy
+func TestPlayGuardedNoDeadline(t *testing.T) {
+	state := loadState(t, "generate_tree.json")
+
+	timeout := game.PlayTimeout
+	game.PlayTimeout = 100 * time.Millisecond
+	defer func() { game.PlayTimeout = timeout }()
+
+	start := time.Now()
+	play := game.NewSession().PlayGuarded(context.Background(), "test-hang", state)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Guard waited %s", elapsed)
+	}
+
+	if err := state.CheckPlay(play); err != nil || play.Pass() {
+		t.Errorf("Illegal play %v: %v", play, err)
+	}
+}
@@ -0,0 +1,25 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/josecleiton/domino/app/game"
+)
+
+// with ctx done no iteration runs, the search still answers a legal play
+func TestISMCTSExpiredContext(t *testing.T) {
+	state := loadState(t, "generate_tree.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	play := game.NewSession().PlayStrategy(ctx, game.ISMCTSStrategy, state)
+	if play.Pass() {
+		t.Fatal("Pass is not allowed")
+	}
+
+	if err := state.CheckPlay(play); err != nil {
+		t.Error(err)
+	}
+}
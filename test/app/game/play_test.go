@@ -2,105 +2,58 @@ package game
 
 import (
 	"container/list"
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/josecleiton/domino/app/game"
 	"github.com/josecleiton/domino/app/models"
 )
 
-func firstPlay() models.DominoGameState {
-	return models.DominoGameState{
-		PlayerPosition: 1,
-		Hand: []models.Domino{
-			{L: 0, R: 0},
-			{L: 0, R: 3},
-			{L: 1, R: 2},
-			{L: 1, R: 3},
-			{L: 1, R: 6},
-			{L: 3, R: 6},
-			{L: 5, R: 5},
-		},
-		TableMap: models.TableMap{},
-		Table:    []models.Domino{},
-		Plays:    []models.DominoPlay{},
-	}
-}
+// loadStates reads the states of a game record fixture in testdata
+func loadStates(tb testing.TB, name string) []*models.DominoGameState {
+	tb.Helper()
 
-func secondPlay(stGameState *models.DominoGameState, stPlay *models.DominoPlayWithPass) models.DominoGameState {
-	plays := []models.DominoPlay{
-		{
-			PlayerPosition: stPlay.PlayerPosition,
-			Bone: models.DominoInTable{
-				Edge:   stPlay.Bone.Edge,
-				Domino: stPlay.Bone.Domino,
-			},
-		},
-		{
-			PlayerPosition: stPlay.PlayerPosition + 1,
-			Bone: models.DominoInTable{
-				Edge: models.LeftEdge,
-				Domino: models.Domino{
-					L: 4,
-					R: 5,
-				},
-			},
-		},
-		{
-			PlayerPosition: stPlay.PlayerPosition + 2,
-			Bone: models.DominoInTable{
-				Edge: models.RightEdge,
-				Domino: models.Domino{
-					L: 5,
-					R: 3,
-				},
-			},
-		},
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		tb.Fatal(err)
 	}
+	defer f.Close()
 
-	table := []models.Domino{
-		plays[1].Bone.Domino,
-		plays[0].Bone.Domino,
-		plays[2].Bone.Domino,
+	records, err := models.ReadGameRecords(f)
+	if err != nil {
+		tb.Fatal(err)
 	}
 
-	tableMap := make(models.TableMap, len(plays))
-	for _, play := range plays {
-		if _, ok := tableMap[play.Bone.L]; !ok {
-			tableMap[play.Bone.L] = make(models.TableBone, len(plays))
-		}
-
-		if _, ok := tableMap[play.Bone.R]; !ok {
-			tableMap[play.Bone.R] = make(models.TableBone, len(plays))
+	states := make([]*models.DominoGameState, 0, len(records))
+	for _, record := range records {
+		state, err := record.State()
+		if err != nil {
+			tb.Fatal(err)
 		}
 
-		tableMap[play.Bone.L][play.Bone.R] = true
-		tableMap[play.Bone.R][play.Bone.L] = true
+		states = append(states, state)
 	}
 
-	newHand := make([]models.Domino, 0, len(stGameState.Hand)-1)
-	for _, bone := range stGameState.Hand {
-		if bone != stPlay.Bone.Domino {
-			newHand = append(newHand, bone)
-		}
-	}
+	return states
+}
 
-	return models.DominoGameState{
-		PlayerPosition: stPlay.PlayerPosition,
-		Hand:           newHand,
-		TableMap:       tableMap,
-		Plays:          plays,
-		Table:          table,
-	}
+func loadState(tb testing.TB, name string) *models.DominoGameState {
+	tb.Helper()
+
+	return loadStates(tb, name)[0]
 }
 
 func TestPlayInitial(t *testing.T) {
-	firstPlay := firstPlay()
+	firstPlay := loadState(t, "first_play.json")
 	if len(firstPlay.Hand) < 1 {
 		t.Fatal("Hand is not empty")
 	}
 
-	play := game.Play(&firstPlay)
+	play := game.Play(context.Background(), firstPlay)
 
 	if play.Pass() {
 		t.Fatal("Pass is not allowed on first play")
@@ -119,32 +72,16 @@ func TestPlayInitial(t *testing.T) {
 
 }
 
-func tableMapFromTable(table []models.Domino) models.TableMap {
-	tableMap := make(models.TableMap, len(table))
-	for _, v := range table {
-		if _, ok := tableMap[v.L]; !ok {
-			tableMap[v.L] = make(models.TableBone, len(table))
-		}
-
-		if _, ok := tableMap[v.R]; !ok {
-			tableMap[v.R] = make(models.TableBone, len(table))
-		}
-
-		tableMap[v.L][v.R] = true
-		tableMap[v.R][v.L] = true
-	}
-
-	return tableMap
-}
-
 func TestPlayGlue(t *testing.T) {
-	stGameState := firstPlay()
-	stPlay := game.Play(&stGameState)
-	ndGameState := secondPlay(&stGameState, &stPlay)
+	stPlay := game.Play(context.Background(), loadState(t, "first_play.json"))
+	ndGameState := loadState(t, "second_play.json")
+	if stPlay.Pass() || !stPlay.Bone.Domino.Equals(ndGameState.Plays[0].Bone.Domino) {
+		t.Fatalf("second_play.json follows %v, not %v", ndGameState.Plays[0].Bone.Domino, stPlay.Bone)
+	}
 
 	fmt.Println("hand:", ndGameState.Hand)
 	fmt.Println("table:", ndGameState.Table)
-	ndPlay := game.Play(&ndGameState)
+	ndPlay := game.Play(context.Background(), ndGameState)
 	if ndPlay.Pass() {
 		fmt.Println("Pass is not allowed")
 		t.FailNow()
@@ -165,218 +102,17 @@ func TestPlayGlue(t *testing.T) {
 }
 
 func TestPassedPlay(t *testing.T) {
-	tableSt := []models.Domino{
-		{L: 0, R: 2},
-		{L: 2, R: 5},
-		{L: 5, R: 5},
-		{L: 5, R: 6},
-		{L: 6, R: 6},
-		{L: 6, R: 0},
-		{L: 0, R: 3},
-		{L: 3, R: 3},
-		{L: 3, R: 6},
-	}
-	tableMapSt := tableMapFromTable(tableSt)
-	gameStateSt := &models.DominoGameState{
-		PlayerPosition: 2,
-		Hand: []models.Domino{
-			{L: 6, R: 1},
-			{L: 5, R: 3},
-			{L: 1, R: 0},
-			{L: 0, R: 0},
-			{L: 4, R: 3},
-		},
-		Plays: []models.DominoPlay{
-			{
-				PlayerPosition: 1,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 6, R: 6},
-				},
-			},
-			{
-				PlayerPosition: 2,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 5, R: 6},
-				},
-			},
-			{
-				PlayerPosition: 3,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 6, R: 0},
-				},
-			},
-			{
-				PlayerPosition: 4,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 5, R: 5},
-				},
-			},
-			{
-				PlayerPosition: 1,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 2, R: 5},
-				},
-			},
-			{
-				PlayerPosition: 2,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 0, R: 3},
-				},
-			},
-			{
-				PlayerPosition: 3,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 0, R: 2},
-				},
-			},
-			{
-				PlayerPosition: 4,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 3, R: 3},
-				},
-			},
-			{
-				PlayerPosition: 1,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 3, R: 6},
-				},
-			},
-		},
-		Table:    tableSt,
-		TableMap: tableMapSt,
-	}
+	states := loadStates(t, "passed_play.jsonl")
+	gameStateSt, gameStateNd := states[0], states[1]
 
-	play := game.Play(gameStateSt)
+	play := game.Play(context.Background(), gameStateSt)
 	if play.Pass() {
 		t.Fatal("Pass is not allowed")
 	}
 
 	fmt.Println("play:", play)
 
-	tableNd := []models.Domino{
-		{L: 5, R: 0},
-		{L: 0, R: 2},
-		{L: 2, R: 5},
-		{L: 5, R: 5},
-		{L: 5, R: 6},
-		{L: 6, R: 6},
-		{L: 6, R: 0},
-		{L: 0, R: 3},
-		{L: 3, R: 3},
-		{L: 3, R: 6},
-		{L: 6, R: 1},
-		{L: 1, R: 3},
-	}
-	tableMapNd := tableMapFromTable(tableNd)
-
-	gameStateNd := &models.DominoGameState{
-		PlayerPosition: 2,
-		Hand: []models.Domino{
-			{L: 5, R: 3},
-			{L: 1, R: 0},
-			{L: 0, R: 0},
-			{L: 4, R: 3},
-		},
-		Plays: []models.DominoPlay{
-			{
-				PlayerPosition: 1,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 6, R: 6},
-				},
-			},
-			{
-				PlayerPosition: 2,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 5, R: 6},
-				},
-			},
-			{
-				PlayerPosition: 3,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 6, R: 0},
-				},
-			},
-			{
-				PlayerPosition: 4,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 5, R: 5},
-				},
-			},
-			{
-				PlayerPosition: 1,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 2, R: 5},
-				},
-			},
-			{
-				PlayerPosition: 2,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 0, R: 3},
-				},
-			},
-			{
-				PlayerPosition: 3,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 0, R: 2},
-				},
-			},
-			{
-				PlayerPosition: 4,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 3, R: 3},
-				},
-			},
-			{
-				PlayerPosition: 1,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 3, R: 6},
-				},
-			},
-			{
-				PlayerPosition: 2,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 6, R: 1},
-				},
-			},
-			{
-				PlayerPosition: 3,
-				Bone: models.DominoInTable{
-					Edge:   models.RightEdge,
-					Domino: models.Domino{L: 1, R: 3},
-				},
-			},
-			{
-				PlayerPosition: 4,
-				Bone: models.DominoInTable{
-					Edge:   models.LeftEdge,
-					Domino: models.Domino{L: 5, R: 0},
-				},
-			},
-		},
-		Table:    tableNd,
-		TableMap: tableMapNd,
-	}
-
-	play = game.Play(gameStateNd)
+	play = game.Play(context.Background(), gameStateNd)
 
 	fmt.Println("play:", play)
 
@@ -386,217 +122,17 @@ func TestPassedPlay(t *testing.T) {
 }
 
 func TestGenerateTree(t *testing.T) {
-	plays := []models.DominoPlay{
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 6, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 1, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 1},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 6, R: 2},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 0},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 3, R: 0},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 5, R: 3},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 2, R: 2},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 5, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 2, R: 1},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 6, R: 0},
-			},
-		},
-	}
-	tableL := list.New()
-
-	for _, p := range plays {
-		if p.Bone.Edge == models.LeftEdge {
-			tableL.PushFront(p.Bone.Domino)
-		} else {
-			tableL.PushBack(p.Bone.Domino)
-		}
-	}
-
-	table := make([]models.Domino, 0, tableL.Len())
-	for current := tableL.Front(); current != nil; current = current.Next() {
-		table = append(table, current.Value.(models.Domino))
-	}
-
-	tableMap := tableMapFromTable(table)
-
-	state := &models.DominoGameState{
-		PlayerPosition: 1,
-		Hand: []models.Domino{
-			{L: 6, R: 5},
-			{L: 1, R: 1},
-			{L: 3, R: 1},
-			{L: 4, R: 1},
-		},
-		Table:    table,
-		TableMap: tableMap,
-		Plays:    plays,
-	}
+	state := loadState(t, "generate_tree.json")
 
-	play := game.Play(state)
+	play := game.Play(context.Background(), state)
 	if play.Pass() {
 		t.Error("Pass is not allowed")
 	}
 
-	newPlays := []models.DominoPlay{
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 6, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 1, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 1},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 6, R: 2},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 0},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 3, R: 0},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 5, R: 3},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 2, R: 2},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 5, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 2, R: 1},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 6, R: 0},
-			},
-		},
-		{
-			PlayerPosition: play.PlayerPosition,
-			Bone:           *play.Bone,
-		},
-	}
+	newPlays := append(state.Plays[:len(state.Plays):len(state.Plays)], models.DominoPlay{
+		PlayerPosition: play.PlayerPosition,
+		Bone:           *play.Bone,
+	})
 	newTableL := list.New()
 
 	for _, p := range newPlays {
@@ -623,156 +159,73 @@ func TestGenerateTree(t *testing.T) {
 		PlayerPosition: play.PlayerPosition,
 		Hand:           newHand,
 		Table:          newTable,
-		TableMap:       tableMapFromTable(newTable),
+		TableMap:       models.TableMapFromDominoes(newTable),
 		Plays:          newPlays,
 	}
 
-	newPlay := game.Play(newState)
+	newPlay := game.Play(context.Background(), newState)
 
 	if newPlay.Pass() {
 		t.Error("Pass is not allowed")
 	}
 }
+
+// the pips the next player passed on count as known, so the tree is
+// generated this late in the game
 func TestDrawPlay(t *testing.T) {
-	plays := []models.DominoPlay{
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 6, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 0, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 5, R: 0},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 5, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 4, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 6, R: 4},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 6, R: 5},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 2, R: 6},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 5, R: 3},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 3, R: 2},
-			},
-		},
-		{
-			PlayerPosition: 1,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 4, R: 2},
-			},
-		},
-		{
-			PlayerPosition: 2,
-			Bone: models.DominoInTable{
-				Edge:   models.LeftEdge,
-				Domino: models.Domino{L: 3, R: 4},
-			},
-		},
-		{
-			PlayerPosition: 3,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 2, R: 1},
-			},
-		},
-		{
-			PlayerPosition: 4,
-			Bone: models.DominoInTable{
-				Edge:   models.RightEdge,
-				Domino: models.Domino{L: 1, R: 6},
-			},
-		},
-	}
-	tableL := list.New()
+	state := loadState(t, "draw_play.json")
 
-	for _, p := range plays {
-		if p.Bone.Edge == models.LeftEdge {
-			tableL.PushFront(p.Bone.Domino)
-		} else {
-			tableL.PushBack(p.Bone.Domino)
-		}
+	play := game.Play(context.Background(), state)
+
+	if !play.Pass() {
+		t.Error("Play is not allowed")
 	}
 
-	table := make([]models.Domino, 0, tableL.Len())
-	for current := tableL.Front(); current != nil; current = current.Next() {
-		table = append(table, current.Value.(models.Domino))
+	tree := game.WaitTreeGeneration()
+
+	if tree == nil {
+		t.Error("Tree not generated")
 	}
 
-	tableMap := tableMapFromTable(table)
-
-	state := &models.DominoGameState{
-		PlayerPosition: 4,
-		Hand: []models.Domino{
-			{L: 1, R: 0},
-			{L: 2, R: 2},
-			{L: 1, R: 1},
-		},
-		Table:    table,
-		TableMap: tableMap,
-		Plays:    plays,
+}
+
+// the tree isn't generated this early, waiting for it must not hang
+func TestTreeGenerationEarly(t *testing.T) {
+	state := loadState(t, "early_play.json")
+
+	session := game.NewSession()
+	if play := session.Play(context.Background(), state); play.Pass() {
+		t.Fatal("Pass is not allowed")
 	}
 
-	play := game.Play(state)
+	done := make(chan struct{})
+	go func() {
+		session.WaitTreeGeneration()
+		close(done)
+	}()
 
-	if !play.Pass() {
-		t.Error("Play is not allowed")
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tree generation never finished")
 	}
+}
 
-	tree := game.WaitTreeGeneration()
+// seat 3 passed on 6, 5, 3 and 2 and every play leaves one of them open,
+// duoPlay indexed its empty filtered plays here
+func TestDuoPlayNoEdgeRespectsDuo(t *testing.T) {
+	state := loadState(t, "duo_passed.json")
 
-	if tree == nil {
-		t.Error("Tree not generated")
+	play := game.NewSession().PlayStrategy(context.Background(), game.HeuristicStrategy, state)
+	if play.Pass() {
+		t.Fatal("Pass is not allowed")
+	}
+
+	for _, legal := range state.LegalPlays() {
+		if *legal.Bone == *play.Bone {
+			return
+		}
 	}
 
+	t.Errorf("Illegal play %v", play)
 }
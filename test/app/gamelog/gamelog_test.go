@@ -0,0 +1,173 @@
+package gamelog
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josecleiton/domino/app/gamelog"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+const closedLog = `Iniciando container do jogador 1... bots/alice
+Iniciando container do jogador 2... bots/bob
+Iniciando container do jogador 3... bots/alice
+Iniciando container do jogador 4... bots/bob
+Iniciando partida...
+
+Pedras distribuídas:
+  Jogador 1: [6-6] [0-1] [0-2] [0-3] [0-4] [0-5] [0-6]
+  Jogador 2: [1-1] [1-2] [1-3] [1-4] [1-5] [1-6] [2-2]
+  Jogador 3: [2-3] [2-4] [2-5] [2-6] [3-3] [3-4] [3-5]
+  Jogador 4: [3-6] [4-4] [4-5] [4-6] [5-5] [5-6] [0-0]
+Jogador 1 começa a partida e coloca a pedra [6-6] na mesa.
+
+  Mesa: [6-6]
+
+  Jogador 2 (*): [1-1] [1-2] [1-3] [1-4] [1-5] [1-6] [2-2]
+
+Jogada recebida:
+{ pedra: '1-6', lado: 'direita' }
+
+Jogador 2 jogou a pedra [1-6] no lado direita da mesa.
+
+Jogador 3 poderia ter jogado a pedra [2-6], mas passou a vez.
+Jogador 3 passou a vez.
+
+Vencedor: bot2.
+`
+
+func TestParse(t *testing.T) {
+	games, err := gamelog.Parse(strings.NewReader(closedLog), "inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(games) != 1 {
+		t.Fatalf("Wrong number of games %d", len(games))
+	}
+
+	game := games[0]
+	if game.Bots[0] != "alice" || game.Bots[3] != "bob" {
+		t.Errorf("Wrong bots %v", game.Bots)
+	}
+
+	if len(game.Deal[2]) != models.DominoHandLength || game.Deal[2][0] != (models.Domino{L: 2, R: 3}) {
+		t.Errorf("Wrong deal %v", game.Deal)
+	}
+
+	if len(game.Turns) != 3 || !game.Turns[2].Pass() || game.Turns[1].Bone.Edge != models.RightEdge {
+		t.Errorf("Wrong turns %v", game.Turns)
+	}
+
+	if game.Outcome != referee.OutcomeDisqualified || game.Player != 3 ||
+		game.Winner != referee.SecondTeam || !errors.Is(game.Err, referee.ErrIllegalPass) {
+		t.Errorf("Wrong result %v %d %v %v", game.Outcome, game.Player, game.Winner, game.Err)
+	}
+
+	result, err := game.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !errors.Is(result.Err, referee.ErrIllegalPass) {
+		t.Errorf("Wrong replay error %v", result.Err)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	_, err := gamelog.Parse(strings.NewReader("Pedras distribuídas:\n  Jogador 1: [0-0]\nJogador 1 passou a vez.\n"), "inline")
+	if !errors.Is(err, gamelog.ErrMalformed) {
+		t.Errorf("Expected a malformed log, got %v", err)
+	}
+}
+
+func TestReplayLogs(t *testing.T) {
+	for _, dir := range gamelog.Dirs {
+		games, err := gamelog.ParseDir(filepath.Join("..", "..", "..", dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(games) == 0 {
+			t.Fatalf("No games in %s", dir)
+		}
+
+		for _, game := range games {
+			result, err := game.Result()
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+
+			if game.Outcome != referee.OutcomeClosed {
+				continue
+			}
+
+			for i, hand := range result.Hands {
+				points := 0
+				for _, bone := range hand {
+					points += bone.Sum()
+				}
+
+				if points != game.Points[i] {
+					t.Errorf("%s: player %d has %d points, not %d", game.Source, i+1, points, game.Points[i])
+				}
+			}
+		}
+	}
+}
+
+func TestDecisions(t *testing.T) {
+	games, err := gamelog.Parse(strings.NewReader(closedLog), "inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decisions, err := games[0].Decisions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decisions) != 2 {
+		t.Fatalf("Wrong number of decisions %d", len(decisions))
+	}
+
+	last := decisions[1]
+	if last.Turn != 2 || last.State.PlayerPosition != 3 || !last.Play.Pass() {
+		t.Errorf("Wrong decision %d %v", last.Turn, last.Play)
+	}
+
+	table := last.State.Table
+	if len(table) != 2 || table[1] != (models.Domino{L: 6, R: 1}) || len(last.State.Hand) != 7 {
+		t.Errorf("Wrong state %v %v", table, last.State.Hand)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	games, err := gamelog.Parse(strings.NewReader(closedLog), "inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := games[0].Record()
+	if len(record.Seats) != models.DominoMaxPlayer || record.Seats[1].Bot != "bob" || len(record.Plays) != 3 {
+		t.Fatalf("Wrong record %v", record)
+	}
+
+	if record.Result.Outcome != referee.OutcomeDisqualified.String() || record.Result.Player != 3 || record.Result.Error == "" {
+		t.Errorf("Wrong result %v", record.Result)
+	}
+
+	record.Player = 3
+	state, err := record.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(state.Table) != 2 || state.Table[1] != (models.Domino{L: 6, R: 1}) || len(state.Hand) != 7 {
+		t.Errorf("Wrong state %v %v", state.Table, state.Hand)
+	}
+}
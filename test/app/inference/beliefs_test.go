@@ -0,0 +1,119 @@
+package inference
+
+import (
+	"math"
+	"testing"
+
+	"github.com/josecleiton/domino/app/inference"
+	"github.com/josecleiton/domino/app/models"
+)
+
+// player 2 passed on 6-6, player 3 played 6-5 and player 4 passed on 6|5
+func passesState() *models.DominoGameState {
+	return &models.DominoGameState{
+		PlayerPosition: 1,
+		Hand: []models.Domino{
+			{L: 0, R: 0}, {L: 0, R: 1}, {L: 1, R: 1},
+			{L: 1, R: 2}, {L: 2, R: 2}, {L: 2, R: 3},
+		},
+		Table: []models.Domino{{L: 6, R: 6}, {L: 6, R: 5}},
+		Plays: []models.DominoPlay{
+			{PlayerPosition: 1, Bone: models.DominoInTable{Domino: models.Domino{L: 6, R: 6}}},
+			{PlayerPosition: 3, Bone: models.DominoInTable{
+				Domino: models.Domino{L: 6, R: 5},
+				Edge:   models.RightEdge,
+			}},
+		},
+	}
+}
+
+func TestHistory(t *testing.T) {
+	turns := inference.History(passesState())
+
+	passes := []models.PlayerPosition{}
+	for _, turn := range turns {
+		if turn.Pass() {
+			passes = append(passes, turn.Player)
+		}
+	}
+
+	if len(turns) != 4 || len(passes) != 2 || passes[0] != 2 || passes[1] != 4 {
+		t.Fatalf("Wrong turns %v", turns)
+	}
+
+	unavailable := inference.Unavailable(turns)
+	if unavailable[1] != models.PipSetOf(6) || unavailable[3] != models.PipSetOf(5, 6) {
+		t.Errorf("Wrong unavailable pips %v", unavailable)
+	}
+}
+
+func TestUnavailableBones(t *testing.T) {
+	play := func(player models.PlayerPosition, l, r int, edge models.Edge) models.DominoPlay {
+		return models.DominoPlay{
+			PlayerPosition: player,
+			Bone:           models.DominoInTable{Domino: models.Domino{L: l, R: r}, Edge: edge},
+		}
+	}
+
+	// 3 and 4 pass on 4|6, 2 passes on 4|3
+	state := &models.DominoGameState{
+		PlayerPosition: 4,
+		Plays: []models.DominoPlay{
+			play(1, 6, 6, models.LeftEdge),
+			play(2, 6, 4, models.LeftEdge),
+			play(1, 6, 3, models.RightEdge),
+			play(3, 3, 2, models.RightEdge),
+		},
+	}
+
+	unavailable := inference.UnavailableBones(state)
+	want := map[models.PlayerPosition][]int{1: {}, 2: {3, 4}, 3: {4, 6}, 4: {4, 6}}
+	for player, pips := range want {
+		bones := unavailable[player]
+		if len(bones) != len(pips) {
+			t.Errorf("Wrong unavailable bones of %d %v", player, bones)
+		}
+
+		for _, pip := range pips {
+			if !bones[pip] {
+				t.Errorf("Wrong unavailable bones of %d %v", player, bones)
+			}
+		}
+	}
+}
+
+func TestBeliefs(t *testing.T) {
+	beliefs := inference.Infer(passesState(), nil)
+
+	if beliefs.Unseen.Len() != 28-8 {
+		t.Fatalf("Wrong unseen bones %d", beliefs.Unseen.Len())
+	}
+
+	for rest := beliefs.Unseen; !rest.Empty(); {
+		var bone models.Domino
+		bone, rest = rest.Pop()
+
+		total := 0.0
+		for player := models.PlayerPosition(2); player <= 4; player++ {
+			total += beliefs.Probability(bone, player)
+		}
+
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("Probabilities of %v sum %f", bone, total)
+		}
+
+		if bone.L == 6 || bone.R == 6 {
+			if p := beliefs.Probability(bone, 2) + beliefs.Probability(bone, 4); p != 0 {
+				t.Errorf("%v is with who passed on 6 with probability %f", bone, p)
+			}
+		}
+	}
+
+	if p := beliefs.Probability(models.Domino{L: 6, R: 4}, 3); p != 1 {
+		t.Errorf("6-4 must be with player 3, not %f", p)
+	}
+
+	if p := beliefs.PipProbability(4, 5); p != 0 {
+		t.Errorf("Player 4 passed on 5 but has it with probability %f", p)
+	}
+}
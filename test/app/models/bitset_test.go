@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+func TestBoneSet(t *testing.T) {
+	all := models.BoneSetOf(models.AllDominoes()...)
+	if all != models.AllBones || all.Len() != models.DominoLength {
+		t.Fatalf("Wrong set of all bones: %b", all)
+	}
+
+	set := models.BoneSetOf(models.Domino{L: 6, R: 4}, models.Domino{L: 0, R: 0})
+	if !set.Has(models.Domino{L: 4, R: 6}) || set.Has(models.Domino{L: 6, R: 6}) {
+		t.Errorf("Wrong bones in %b", set)
+	}
+
+	if set.Sum() != 10 {
+		t.Errorf("Wrong sum %d", set.Sum())
+	}
+
+	if bone, rest := set.Pop(); bone != (models.Domino{L: 0, R: 0}) || rest.Len() != 1 {
+		t.Errorf("Wrong pop %v %b", bone, rest)
+	}
+
+	if set.Avoiding(models.PipSetOf(4)) != models.BoneSetOf(models.Domino{L: 0, R: 0}) {
+		t.Errorf("Wrong bones avoiding 4 in %b", set)
+	}
+
+	if models.BonesWithPip(3).Intersect(models.AllBones).Len() != models.DominoUniqueBones {
+		t.Errorf("Wrong bones with pip 3")
+	}
+
+	if models.BonesWithPip(3).Len() != models.DoubleTwelve.UniqueBones() {
+		t.Errorf("Wrong bones with pip 3 in double-twelve")
+	}
+
+	if pips := set.Pips(); pips != models.PipSetOf(0, 4, 6) {
+		t.Errorf("Wrong pips %b", pips)
+	}
+}
+
+func TestBoneSetConversions(t *testing.T) {
+	dominoes := []models.Domino{{L: 6, R: 6}, {L: 6, R: 2}, {L: 2, R: 1}}
+
+	if set := models.TableMapFromDominoes(dominoes).BoneSet(); set != models.BoneSetOf(dominoes...) {
+		t.Errorf("Wrong table set %b", set)
+	}
+
+	unavailable := models.UnavailableBonesPlayer{
+		2: models.TableBone{1: true, 5: true, 3: false},
+	}
+
+	sets := unavailable.PipSets()
+	if sets[1] != models.PipSetOf(1, 5) || sets[0] != 0 {
+		t.Errorf("Wrong pip sets %v", sets)
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+func TestGameRecord(t *testing.T) {
+	record := models.GameRecord{
+		Seats: []models.SeatRecord{
+			{Player: 1, Bot: "alice", Hand: []string{"6-6", "0-1", "0-2", "0-3", "0-4", "0-5", "0-6"}},
+			{Player: 2, Bot: "bob", Hand: []string{"1-1", "1-2", "1-3", "1-4", "1-5", "1-6", "2-2"}},
+		},
+		Player: 1,
+		Plays: []models.PlayRecord{
+			{Player: 1, Bone: "6-6"},
+			{Player: 2, Bone: "1-6", Side: models.SideRight},
+			{Player: 3},
+			{Player: 4},
+			{Player: 1, Bone: "0-6", Side: models.SideLeft},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := models.WriteGameRecord(&buf, record); err != nil {
+		t.Fatal(err)
+	}
+	if err := models.WriteGameRecord(&buf, record); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := models.ReadGameRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || records[1].Version != models.GameRecordVersion {
+		t.Fatalf("Wrong records %v", records)
+	}
+
+	state, err := records[1].State()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(state.Hand) != models.DominoHandLength-2 || len(state.Plays) != 3 {
+		t.Errorf("Wrong hand %v or plays %v", state.Hand, state.Plays)
+	}
+
+	if models.TableString(state.Table) != models.TableString([]models.Domino{{L: 0, R: 6}, {L: 6, R: 6}, {L: 6, R: 1}}) {
+		t.Errorf("Wrong table %v", state.Table)
+	}
+
+	back := models.GameRecordFromState(state)
+	if len(back.Plays) != 3 || back.Plays[1].Side != models.SideRight || len(back.Table) != 3 {
+		t.Errorf("Wrong record of state %v", back)
+	}
+}
+
+func TestGameRecordInvalid(t *testing.T) {
+	if _, err := models.ReadGameRecords(strings.NewReader(`{"version":99,"jogador":1,"jogadas":[]}`)); !errors.Is(err, models.ErrRecordVersion) {
+		t.Errorf("Wrong error %v", err)
+	}
+
+	record := models.GameRecord{Player: 2, Plays: []models.PlayRecord{{Player: 1, Bone: "6-6", Side: "cima"}}}
+	if _, err := record.State(); !errors.Is(err, models.ErrRecordInvalid) {
+		t.Errorf("Wrong error %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+func TestBatidaOf(t *testing.T) {
+	cases := []struct {
+		bone        models.Domino
+		left, right int
+		batida      models.Batida
+	}{
+		{models.Domino{L: 2, R: 5}, 2, 3, models.BatidaPlain},
+		{models.Domino{L: 4, R: 4}, 4, 1, models.BatidaCarroca},
+		{models.Domino{L: 3, R: 1}, 1, 3, models.BatidaLaELo},
+		{models.Domino{L: 6, R: 6}, 6, 6, models.BatidaCruzada},
+	}
+
+	for _, c := range cases {
+		if batida := models.BatidaOf(c.bone, c.left, c.right); batida != c.batida {
+			t.Errorf("Wrong batida %s of %v on %d|%d", batida, c.bone, c.left, c.right)
+		}
+	}
+
+	if points := models.BrazilianRules.Points(models.BatidaCruzada, 30); points != 4 {
+		t.Errorf("Wrong cruzada points %d", points)
+	}
+
+	if points := models.PointsRules.Points(models.BatidaNone, 17); points != 17 {
+		t.Errorf("Wrong closed points %d", points)
+	}
+
+	if points := models.ClassicRules.Points(models.BatidaCarroca, 17); points != 1 {
+		t.Errorf("Wrong classic points %d", points)
+	}
+}
+
+func TestRulesetOpening(t *testing.T) {
+	held := models.BoneSetOf(models.Domino{L: 6, R: 5}, models.Domino{L: 2, R: 2}, models.Domino{L: 4, R: 4})
+
+	if _, ok := models.ClassicRules.Opening(held); ok {
+		t.Error("Classic rules opened without 6-6")
+	}
+
+	if opening, ok := models.BrazilianRules.Opening(held); !ok || opening != (models.Domino{L: 4, R: 4}) {
+		t.Errorf("Wrong opening %v", opening)
+	}
+
+	noDoubles := models.BoneSetOf(models.Domino{L: 1, R: 0}, models.Domino{L: 6, R: 5})
+	if opening, _ := models.BrazilianRules.Opening(noDoubles); opening != (models.Domino{L: 5, R: 6}) {
+		t.Errorf("Wrong opening without doubles %v", opening)
+	}
+}
+
+// on an empty table only the opening of the rules is legal
+func TestStateOpening(t *testing.T) {
+	sixSix, sixFive := models.Domino{L: 6, R: 6}, models.Domino{L: 6, R: 5}
+	state := models.DominoGameState{PlayerPosition: 1, Hand: []models.Domino{sixFive, sixSix}}
+
+	plays := state.LegalPlays()
+	if len(plays) != 1 || !plays[0].Bone.Domino.Equals(sixSix) {
+		t.Errorf("Wrong opening plays %v", plays)
+	}
+
+	open := func(bone models.Domino) models.DominoPlayWithPass {
+		return models.DominoPlayWithPass{PlayerPosition: 1, Bone: &models.DominoInTable{Edge: models.LeftEdge, Domino: bone}}
+	}
+
+	if err := state.CheckPlay(open(sixFive)); err == nil {
+		t.Error("Opened with 6-5 holding 6-6")
+	}
+
+	if err := state.CheckPlay(open(sixSix)); err != nil {
+		t.Error(err)
+	}
+
+	// under the brazilian rules the highest double opens without 6-6
+	state = models.DominoGameState{
+		PlayerPosition: 1,
+		Hand:           []models.Domino{sixFive, {L: 4, R: 4}},
+		Goal:           &models.Goal{Rules: models.BrazilianRules},
+	}
+	if err := state.CheckPlay(open(sixFive)); err == nil {
+		t.Error("Opened with 6-5 holding 4-4")
+	}
+}
+
+func TestParseGoal(t *testing.T) {
+	rules := models.Ruleset{Target: 50, Bonuses: true, Pips: true}
+
+	goal, err := models.ParseGoal(rules.String(), "12-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if goal.Rules != rules || goal.Points != 12 || goal.Against != 30 {
+		t.Errorf("Wrong goal %v", goal)
+	}
+
+	if rules, err := models.ParseRuleset("brazilian"); err != nil || rules != models.BrazilianRules {
+		t.Errorf("Wrong ruleset %v", rules)
+	}
+
+	if _, err := models.ParseRuleset("target=6,trump"); err == nil {
+		t.Error("Unknown rule accepted")
+	}
+
+	if goal.Value(true, 40) != 1 || goal.Value(false, 20) != 0 {
+		t.Error("Wrong value of the last hand")
+	}
+
+	if goal.Value(true, 4) <= goal.Value(true, 1) || goal.Value(true, 1) <= goal.Value(false, 1) {
+		t.Error("Value doesn't grow with the points")
+	}
+}
+
+func TestDominoSet(t *testing.T) {
+	for _, set := range []models.DominoSet{models.DoubleSix, models.DoubleNine, models.DoubleTwelve} {
+		dominoes := set.Dominoes()
+		if len(dominoes) != set.Length() || set.Bones() != models.BoneSetOf(dominoes...) {
+			t.Errorf("Wrong bones of %s", set)
+		}
+
+		for _, bone := range dominoes {
+			if models.BoneAt(bone.Index()) != bone {
+				t.Fatalf("Wrong index %d of %v", bone.Index(), bone)
+			}
+		}
+	}
+
+	if models.DoubleNine.Length() != 55 || models.DoubleTwelve.Length() != 91 {
+		t.Errorf("Wrong set lengths")
+	}
+
+	// double-six keeps the order of AllDominoes
+	if models.DoubleSix.Bones() != models.AllBones || (models.Domino{L: 6, R: 6}).Index() != models.DominoLength-1 {
+		t.Errorf("Wrong double-six bones")
+	}
+
+	set := models.BoneSetOf(models.Domino{L: 12, R: 11}, models.Domino{L: 0, R: 1})
+	if bone, rest := set.Pop(); bone != (models.Domino{L: 0, R: 1}) || rest != models.BoneSetOf(models.Domino{L: 11, R: 12}) {
+		t.Errorf("Wrong pop %v", bone)
+	}
+
+	if s := (models.Domino{L: 9, R: 3}).String(); s != "[9|3]" {
+		t.Errorf("Wrong string %q", s)
+	}
+
+	rules, err := models.ParseRuleset("brazilian,set=double-twelve")
+	if err != nil || rules.Set != models.DoubleTwelve || rules.Target != models.BrazilianRules.Target {
+		t.Fatalf("Wrong ruleset %v %v", rules, err)
+	}
+
+	if parsed, _ := models.ParseRuleset(rules.String()); parsed != rules {
+		t.Errorf("Wrong ruleset %v from %q", parsed, rules.String())
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/josecleiton/domino/app/models"
+)
+
+func play(player models.PlayerPosition, l, r int, edge models.Edge) models.DominoPlay {
+	return models.DominoPlay{
+		PlayerPosition: player,
+		Bone:           models.DominoInTable{Domino: models.Domino{L: l, R: r}, Edge: edge},
+	}
+}
+
+func TestReplayTable(t *testing.T) {
+	replay := models.ReplayTable([]models.DominoPlay{
+		play(1, 6, 6, models.LeftEdge),
+		play(2, 6, 4, models.LeftEdge),
+		play(3, 6, 1, models.RightEdge),
+		play(4, 5, 5, models.LeftEdge),
+		play(1, 6, 4, models.RightEdge),
+		play(2, 2, 4, models.LeftEdge),
+	})
+
+	chain := []models.Domino{{L: 2, R: 4}, {L: 4, R: 6}, {L: 6, R: 6}, {L: 6, R: 1}}
+	if models.TableString(replay.Chain) != models.TableString(chain) {
+		t.Fatalf("Wrong chain %v", replay.Chain)
+	}
+
+	if len(replay.Discrepancies) != 2 ||
+		replay.Discrepancies[0].Kind != models.DiscrepancyUnglued || replay.Discrepancies[0].Play != 3 ||
+		replay.Discrepancies[1].Kind != models.DiscrepancyRepeated || replay.Discrepancies[1].Play != 4 {
+		t.Errorf("Wrong discrepancies %v", replay.Discrepancies)
+	}
+
+	if discrepancies := replay.Check(chain); len(discrepancies) != 0 {
+		t.Errorf("Wrong discrepancies of the same table %v", discrepancies)
+	}
+
+	turned := []models.Domino{{L: 2, R: 4}, {L: 6, R: 4}, {L: 6, R: 6}, {L: 6, R: 2}}
+	discrepancies := replay.Check(turned)
+	if len(discrepancies) != 2 ||
+		discrepancies[0].Kind != models.DiscrepancyFlipped || discrepancies[0].Position != 1 ||
+		discrepancies[1].Kind != models.DiscrepancyBone || discrepancies[1].Position != 3 {
+		t.Errorf("Wrong discrepancies %v", discrepancies)
+	}
+
+	discrepancies = replay.Check([]models.Domino{{L: 4, R: 6}, {L: 6, R: 6}, {L: 6, R: 0}})
+	if len(discrepancies) != 4 ||
+		discrepancies[0].Kind != models.DiscrepancyLength ||
+		discrepancies[1].Kind != models.DiscrepancyMissing || *discrepancies[1].Want != chain[0] ||
+		discrepancies[2].Kind != models.DiscrepancyMissing || *discrepancies[2].Want != chain[3] ||
+		discrepancies[3].Kind != models.DiscrepancyExtra || discrepancies[3].Position != 2 {
+		t.Errorf("Wrong discrepancies %v", discrepancies)
+	}
+}
@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/josecleiton/domino/app/gamelog"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/profile"
+)
+
+func TestBuild(t *testing.T) {
+	games, err := gamelog.ParseDir(filepath.Join("..", "..", "..", gamelog.Dirs[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := profile.Build(games)
+
+	basco := profiles["1Basco"]
+	if basco == nil || basco.Games == 0 || basco.Decisions == 0 {
+		t.Fatalf("Wrong profile %v", basco)
+	}
+
+	// 1Basco drops its doubles as soon as it can
+	if basco.EarlyDoubles.Chances < 20 || basco.EarlyDoubles.Bias() < 1.5 {
+		t.Errorf("Wrong early doubles %v", basco.EarlyDoubles)
+	}
+
+	name := filepath.Join(t.TempDir(), "profiles.json")
+	if err := profiles.Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := profile.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *loaded["1Basco"] != *basco {
+		t.Errorf("Wrong loaded profile %v", loaded["1Basco"])
+	}
+
+	s := profile.Situation{
+		Player:     2,
+		Hand:       models.BoneSetOf(models.Domino{L: 6, R: 6}, models.Domino{L: 6, R: 1}, models.Domino{L: 1, R: 1}),
+		Left:       6,
+		Right:      1,
+		Turn:       1,
+		PartnerPip: -1,
+	}
+
+	bones, weights := basco.Weights(s)
+	if len(bones) != 3 {
+		t.Fatalf("Wrong options %v", bones)
+	}
+
+	total, doubles := 0.0, 0.0
+	for i, bone := range bones {
+		total += weights[i]
+		if bone.L == bone.R {
+			doubles += weights[i]
+		}
+	}
+
+	if math.Abs(total-1) > 1e-9 || doubles < 0.9 {
+		t.Errorf("Wrong weights %v of %v", weights, bones)
+	}
+}
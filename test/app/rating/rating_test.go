@@ -0,0 +1,61 @@
+package rating
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josecleiton/domino/app/rating"
+)
+
+func TestLedger(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "ratings.json")
+
+	ledger, err := rating.Load(name)
+	if err != nil || len(ledger.Entries) != 0 {
+		t.Fatalf("Wrong empty ledger %v %v", ledger, err)
+	}
+
+	strong := ledger.Entry(rating.Identity{Name: "pimc", Commit: "abc1234", Params: "timeout=50ms"})
+	weak := ledger.Entry(rating.Identity{Name: "glue"})
+	if ledger.Entry(strong.Identity) != strong || len(ledger.Entries) != 2 {
+		t.Fatalf("Entry not reused: %v", ledger.Entries)
+	}
+
+	ledger.Record(strong, weak, 70, 30)
+
+	if strong.Games != 100 || strong.Wins != 70 || weak.Wins != 30 {
+		t.Errorf("Wrong counts %+v %+v", strong, weak)
+	}
+
+	if math.Abs(strong.Elo+weak.Elo-2*rating.InitialElo) > 1e-6 || strong.Elo <= weak.Elo {
+		t.Errorf("Wrong elo %f %f", strong.Elo, weak.Elo)
+	}
+
+	if strong.Mu <= weak.Mu || strong.Sigma >= rating.InitialSigma || ledger.Sorted()[0] != strong {
+		t.Errorf("Wrong trueskill %f±%f %f±%f", strong.Mu, strong.Sigma, weak.Mu, weak.Sigma)
+	}
+
+	if err := ledger.Save(name); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := rating.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entry := loaded.Entry(strong.Identity); entry.Elo != strong.Elo || entry.Games != 100 || len(loaded.Entries) != 2 {
+		t.Errorf("Wrong loaded entry %+v", entry)
+	}
+
+	if err := os.WriteFile(name, []byte(`{"version":99}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rating.Load(name); !errors.Is(err, rating.ErrLedgerVersion) {
+		t.Errorf("Wrong error %v", err)
+	}
+}
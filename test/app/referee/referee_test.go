@@ -0,0 +1,182 @@
+package referee
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+)
+
+func gluePlayer(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	edges := state.Edges()
+	for _, bone := range state.Hand {
+		for _, edge := range []models.Edge{models.LeftEdge, models.RightEdge} {
+			table := models.DominoInTable{Edge: edge, Domino: *edges[edge]}
+			if table.Glue(bone) == nil {
+				continue
+			}
+
+			return models.DominoPlayWithPass{
+				PlayerPosition: state.PlayerPosition,
+				Bone:           &models.DominoInTable{Edge: edge, Domino: bone},
+			}, nil
+		}
+	}
+
+	return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}, nil
+}
+
+func TestDeal(t *testing.T) {
+	hands := referee.Deal(rand.New(rand.NewSource(1)))
+
+	seen := make(map[models.Domino]bool, models.DominoLength)
+	for _, hand := range hands {
+		if len(hand) != models.DominoHandLength {
+			t.Fatalf("hand with %d bones", len(hand))
+		}
+
+		for _, bone := range hand {
+			seen[bone] = true
+		}
+	}
+
+	if len(seen) != models.DominoLength {
+		t.Fatalf("dealt %d unique bones", len(seen))
+	}
+}
+
+func TestMatchRun(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	match := referee.Match{}
+	for i := range match.Players {
+		match.Players[i] = referee.PlayerFunc(gluePlayer)
+	}
+
+	for i := 0; i < 500; i++ {
+		result := match.Run(referee.Deal(rng))
+
+		if result.Outcome == referee.OutcomeDisqualified {
+			t.Fatalf("legal player disqualified: %v", result.Err)
+		}
+
+		if (result.Plays[0].Bone.Domino != models.Domino{L: 6, R: 6}) {
+			t.Fatalf("game opened with %v", result.Plays[0].Bone.Domino)
+		}
+
+		bones := len(result.Plays)
+		for _, hand := range result.Hands {
+			bones += len(hand)
+		}
+
+		if bones != models.DominoLength {
+			t.Fatalf("%d bones after the game", bones)
+		}
+
+		switch result.Outcome {
+		case referee.OutcomeDomino:
+			if len(result.Hands.Of(result.Player)) != 0 {
+				t.Fatalf("player %d won holding bones", result.Player)
+			}
+
+			if result.Winner != referee.TeamOf(result.Player) {
+				t.Fatalf("wrong winner %d", result.Winner)
+			}
+		case referee.OutcomeClosed:
+			first, second := result.Points(referee.FirstTeam), result.Points(referee.SecondTeam)
+			if first < second && result.Winner != referee.FirstTeam ||
+				first > second && result.Winner != referee.SecondTeam ||
+				first == second && result.Winner == referee.TeamOf(result.Player) {
+				t.Fatalf("wrong closed game winner %d (%d x %d)", result.Winner, first, second)
+			}
+		}
+	}
+}
+
+func TestMatchDisqualifiesIllegalPass(t *testing.T) {
+	pass := referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+		return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}, nil
+	})
+
+	match := referee.Match{}
+	for i := range match.Players {
+		match.Players[i] = pass
+	}
+
+	result := match.Run(referee.Deal(rand.New(rand.NewSource(7))))
+
+	if result.Outcome != referee.OutcomeDisqualified || !errors.Is(result.Err, referee.ErrIllegalPass) {
+		t.Fatalf("expected illegal pass, got %v", result.Err)
+	}
+
+	if result.Winner == referee.TeamOf(result.Player) {
+		t.Fatal("disqualified team won")
+	}
+}
+
+func TestRunSeries(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+
+	match := referee.Match{Rules: models.BrazilianRules}
+	goals := 0
+	for i := range match.Players {
+		match.Players[i] = referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+			if state.Goal == nil || state.Goal.Rules != models.BrazilianRules {
+				t.Fatalf("wrong goal %v", state.Goal)
+			}
+
+			goals++
+
+			return gluePlayer(state)
+		})
+	}
+
+	series := match.RunSeries(func() referee.Hands {
+		return referee.Deal(rng)
+	})
+
+	if goals == 0 || len(series.Hands) < 2 {
+		t.Fatalf("series of %d hands", len(series.Hands))
+	}
+
+	points := [2]int{}
+	for _, result := range series.Hands {
+		if result.Score < 1 || result.Outcome == referee.OutcomeDomino && result.Score != int(result.Batida) {
+			t.Fatalf("hand %s worth %d", result.Batida, result.Score)
+		}
+
+		points[result.Winner-referee.FirstTeam] += result.Score
+	}
+
+	if points != series.Points || !match.Rules.Over(points[series.Winner-referee.FirstTeam]) ||
+		match.Rules.Over(points[series.Winner.Other()-referee.FirstTeam]) {
+		t.Fatalf("wrong series %v won by %d", series.Points, series.Winner)
+	}
+}
+
+func TestDealSet(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	match := referee.Match{Rules: models.Ruleset{Set: models.DoubleNine}}
+	for i := range match.Players {
+		match.Players[i] = referee.PlayerFunc(gluePlayer)
+	}
+
+	for i := 0; i < 200; i++ {
+		deal := referee.DealSet(rng, models.DoubleNine)
+		if deal.Bones().Len() != models.DominoMaxPlayer*models.DoubleNine.HandLength() {
+			t.Fatalf("dealt %d bones", deal.Bones().Len())
+		}
+
+		result := match.Run(deal)
+		if result.Outcome == referee.OutcomeDisqualified {
+			t.Fatalf("legal player disqualified: %v", result.Err)
+		}
+
+		if opening := result.Plays[0].Bone.Domino; !opening.IsDouble() {
+			t.Fatalf("game opened with %v", opening)
+		}
+	}
+}
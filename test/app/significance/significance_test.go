@@ -0,0 +1,49 @@
+package significance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/josecleiton/domino/app/significance"
+)
+
+func TestScore(t *testing.T) {
+	s := significance.Score{Wins: 60, Losses: 40}
+
+	lower, upper := s.Interval(0.95)
+	if math.Abs(lower-0.5020) > 1e-3 || math.Abs(upper-0.6906) > 1e-3 {
+		t.Errorf("Wrong interval [%f, %f]", lower, upper)
+	}
+
+	if p := s.PValue(); math.Abs(p-0.0569) > 1e-3 {
+		t.Errorf("Wrong p-value %f", p)
+	}
+
+	if p := (significance.Score{Wins: 50, Losses: 50}).PValue(); p != 1 {
+		t.Errorf("Wrong p-value of an even score %f", p)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	test := significance.DefaultSPRT
+
+	cases := []struct {
+		score   significance.Score
+		verdict significance.Verdict
+	}{
+		{significance.Score{Wins: 60, Losses: 40}, significance.Undecided},
+		{significance.Score{Wins: 550, Losses: 450}, significance.Stronger},
+		{significance.Score{Wins: 450, Losses: 550}, significance.Weaker},
+		{significance.Score{Wins: 500, Losses: 500}, significance.Equivalent},
+	}
+
+	for _, c := range cases {
+		if verdict := test.Verdict(c.score); verdict != c.verdict {
+			t.Errorf("Wrong verdict %s of %v, expected %s", verdict, c.score, c.verdict)
+		}
+	}
+
+	if remaining := test.Remaining(significance.Score{Wins: 60, Losses: 40}); remaining <= 0 {
+		t.Errorf("Wrong remaining games %d", remaining)
+	}
+}
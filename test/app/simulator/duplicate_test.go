@@ -0,0 +1,47 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/simulator"
+)
+
+// against itself every deal is won from one side and lost from the other
+func TestRunDuplicate(t *testing.T) {
+	player, err := simulator.StrategyPlayer(game.GlueStrategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, seatings := range []bool{false, true} {
+		stats := simulator.RunDuplicate(20, 3, models.ClassicRules, player, player, seatings)
+
+		games := 2 * stats.Deals
+		if seatings {
+			games *= 6
+		}
+
+		if stats.Deals != 20 || stats.Games != games || stats.Wins != games/2 {
+			t.Errorf("Wrong stats %+v", stats.Stats)
+		}
+
+		if stats.Sweeps != 0 || stats.Swept != 0 || stats.StdErr() != 0 {
+			t.Errorf("Luck of the deal didn't cancel out: %d %d %f", stats.Sweeps, stats.Swept, stats.StdErr())
+		}
+	}
+}
+
+// every game of a duplicate run is a match under the rules
+func TestRunDuplicateRules(t *testing.T) {
+	player, err := simulator.StrategyPlayer(game.GlueStrategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := simulator.RunDuplicate(5, 3, models.BrazilianRules, player, player, false)
+	if stats.Games != 10 || stats.Domino+stats.Closed <= stats.Games {
+		t.Errorf("Games aren't matches to %d points %+v", models.BrazilianRules.Target, stats.Stats)
+	}
+}
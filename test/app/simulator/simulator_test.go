@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/josecleiton/domino/app/models"
+	"github.com/josecleiton/domino/app/referee"
+	"github.com/josecleiton/domino/app/simulator"
+)
+
+// firstPlayer plays the first bone of the hand that glues
+var firstPlayer = referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	for _, bone := range state.Hand {
+		if len(state.Table) == 0 {
+			return models.DominoPlayWithPass{
+				PlayerPosition: state.PlayerPosition,
+				Bone:           &models.DominoInTable{Edge: models.LeftEdge, Domino: bone},
+			}, nil
+		}
+
+		ends := map[models.Edge]models.Domino{
+			models.LeftEdge:  state.Table[0],
+			models.RightEdge: state.Table[len(state.Table)-1],
+		}
+		for _, edge := range []models.Edge{models.LeftEdge, models.RightEdge} {
+			end := models.DominoInTable{Edge: edge, Domino: ends[edge]}
+			if end.Glue(bone) != nil {
+				return models.DominoPlayWithPass{
+					PlayerPosition: state.PlayerPosition,
+					Bone:           &models.DominoInTable{Edge: edge, Domino: bone},
+				}, nil
+			}
+		}
+	}
+
+	return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}, nil
+})
+
+// passPlayer always passes, it's disqualified the first time a bone glues
+var passPlayer = referee.PlayerFunc(func(state *models.DominoGameState) (models.DominoPlayWithPass, error) {
+	return models.DominoPlayWithPass{PlayerPosition: state.PlayerPosition}, nil
+})
+
+func TestRunDeals(t *testing.T) {
+	stats := simulator.Run(10, 7, firstPlayer, firstPlayer)
+
+	if stats.Games != 10 || stats.Wins+stats.Losses != 10 ||
+		stats.Domino+stats.Closed != 10 || stats.Disqualified != 0 {
+		t.Errorf("Wrong stats %+v", stats)
+	}
+
+	// the seed deals the same games
+	if again := simulator.Run(10, 7, firstPlayer, firstPlayer); !reflect.DeepEqual(stats, again) {
+		t.Errorf("Same seed, different stats %+v %+v", stats, again)
+	}
+}
+
+func TestRunScoring(t *testing.T) {
+	stats := simulator.Run(4, 1, firstPlayer, passPlayer)
+	if stats.Wins != 4 || stats.Disqualified != 4 || len(stats.Errors) != 0 || stats.WinRate() != 1 {
+		t.Errorf("Wrong stats against the disqualified player %+v", stats)
+	}
+
+	// only the disqualifications of the evaluated player keep their reason
+	stats = simulator.Run(4, 1, passPlayer, firstPlayer)
+	if stats.Losses != 4 || stats.Disqualified != 4 || len(stats.Errors) != 4 || stats.WinRate() != 0 {
+		t.Errorf("Wrong stats of the disqualified player %+v", stats)
+	}
+}
@@ -0,0 +1,156 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/josecleiton/domino/app/game"
+	"github.com/josecleiton/domino/app/gamelog"
+	"github.com/josecleiton/domino/app/significance"
+	"github.com/josecleiton/domino/app/tournament"
+)
+
+func TestRun(t *testing.T) {
+	entrants := []tournament.Entrant{}
+	for _, spec := range []string{game.GlueStrategy, tournament.RandomEntrant, "other=" + tournament.RandomEntrant} {
+		entrant, err := tournament.ParseEntrant(spec, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entrants = append(entrants, entrant)
+	}
+
+	dir := t.TempDir()
+	results, err := tournament.Tournament{
+		Entrants: entrants,
+		Rounds:   3,
+		Games:    2,
+		Seed:     7,
+		Parallel: 4,
+		LogDir:   dir,
+	}.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results.Pairings) != 3 || results.Pairings[2].A != tournament.RandomEntrant || results.Pairings[2].B != "other" {
+		t.Fatalf("Wrong pairings %v", results.Pairings)
+	}
+
+	wins, games := 0, 0
+	for _, standing := range results.Standings {
+		wins += standing.Wins
+		games += standing.Games
+		if standing.Games != 12 || standing.Wins+standing.Losses != standing.Games {
+			t.Errorf("Wrong standing %v", standing)
+		}
+	}
+
+	if wins != 18 || games != 36 {
+		t.Errorf("Wrong totals %d/%d", wins, games)
+	}
+
+	logged, err := gamelog.ParseDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logged) != 18 {
+		t.Fatalf("Wrong number of logged games %d", len(logged))
+	}
+
+	for _, g := range logged {
+		if _, err := g.Result(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// the random entrants are seeded by the tournament, not by the order the
+// parallel games run in
+func TestRunSeed(t *testing.T) {
+	entrants := []tournament.Entrant{}
+	for _, spec := range []string{tournament.RandomEntrant, "other=" + tournament.RandomEntrant} {
+		entrant, err := tournament.ParseEntrant(spec, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entrants = append(entrants, entrant)
+	}
+
+	run := func() tournament.Results {
+		results, err := tournament.Tournament{
+			Entrants: entrants,
+			Rounds:   4,
+			Games:    5,
+			Seed:     11,
+			Parallel: 4,
+		}.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return results
+	}
+
+	if first, second := run(), run(); !reflect.DeepEqual(first, second) {
+		t.Errorf("Same seed, different results %v %v", first.Pairings, second.Pairings)
+	}
+}
+
+// the SPRT stops a pairing between rounds, at the same game on every run
+func TestRunSPRT(t *testing.T) {
+	entrants := []tournament.Entrant{}
+	for _, spec := range []string{game.GlueStrategy, tournament.RandomEntrant} {
+		entrant, err := tournament.ParseEntrant(spec, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entrants = append(entrants, entrant)
+	}
+
+	run := func() tournament.Pairing {
+		results, err := tournament.Tournament{
+			Entrants: entrants,
+			Rounds:   20,
+			Games:    3,
+			Seed:     5,
+			Parallel: 8,
+			SPRT:     &significance.SPRT{P0: 0.5, P1: 0.8, Alpha: 0.1, Beta: 0.1},
+		}.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return results.Pairings[0]
+	}
+
+	first := run()
+	if !first.Stopped || first.Games()%3 != 0 || first.Games() == 60 {
+		t.Fatalf("Pairing not stopped between rounds %+v", first)
+	}
+
+	for i := 0; i < 3; i++ {
+		if again := run(); again.Games() != first.Games() || again.WinsA != first.WinsA {
+			t.Errorf("Stopped at %d games, then at %d", first.Games(), again.Games())
+		}
+	}
+}
+
+func TestParseEntrant(t *testing.T) {
+	if _, err := tournament.ParseEntrant("unknown", nil); err == nil {
+		t.Error("Unknown strategy accepted")
+	}
+
+	if _, err := tournament.ParseEntrant(tournament.ProfilePrefix+"nobody", nil); err == nil {
+		t.Error("Unknown profile accepted")
+	}
+
+	entrant, err := tournament.ParseEntrant("mine=http://localhost:8000", nil)
+	if err != nil || entrant.Name != "mine" {
+		t.Errorf("Wrong entrant %v %v", entrant.Name, err)
+	}
+}